@@ -0,0 +1,349 @@
+package audio
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Sound names an effect loaded once and played on game events
+type Sound string
+
+// Known effects
+const (
+	Shoot     Sound = "shoot"
+	Hit       Sound = "hit"
+	Explosion Sound = "explosion"
+	Pickup    Sound = "pickup"
+)
+
+// Category groups effects sharing volume and channels
+type Category int
+
+const (
+	Weapon Category = iota
+	Impact
+	Blast
+	Bonus
+)
+
+// Categories lists all categories in channel order
+var Categories = []Category{Weapon, Impact, Blast, Bonus}
+
+// ChannelLimits is how many effects of a category may sound at once,
+// the oldest one is cut off when limit is reached
+var ChannelLimits = map[Category]int{
+	Weapon: 4,
+	Impact: 4,
+	Blast:  4,
+	Bonus:  2,
+}
+
+// DefaultSounds maps effects to categories and file names without extension
+var DefaultSounds = map[Sound]Category{
+	Shoot:     Weapon,
+	Hit:       Impact,
+	Explosion: Blast,
+	Pickup:    Bonus,
+}
+
+// Extensions tried when loading effect by name
+var Extensions = []string{".wav", ".ogg"}
+
+// Manager plays effects, when audio device can't be opened
+// it stays silent and every call is a no-op
+type Manager struct {
+	mu         sync.Mutex
+	open       bool
+	chunks     map[Sound]*mix.Chunk
+	categories map[Sound]Category
+	// Volumes in percent
+	master, music, effects int
+	volumes                map[Category]int
+	// Music plays background tracks
+	Music *Music
+	// Positional sound listener and playfield size
+	listenerX, listenerY float64
+	fieldW, fieldH       float64
+}
+
+// MaxAttenuation is the distance value given to SDL_mixer for
+// a sound in the far corner of the playfield, 255 would be silent
+const MaxAttenuation = 160
+
+// NewManager opens audio device, on headless machines set
+// SDL_AUDIODRIVER=dummy to get working but silent output,
+// error means the manager is silent
+func NewManager() (*Manager, error) {
+	m := &Manager{
+		chunks:     make(map[Sound]*mix.Chunk),
+		categories: make(map[Sound]Category),
+		master:     100,
+		music:      100,
+		effects:    100,
+		volumes:    make(map[Category]int),
+	}
+	m.Music = newMusic(m)
+	for _, c := range Categories {
+		m.volumes[c] = 100
+	}
+	if err := sdl.InitSubSystem(sdl.INIT_AUDIO); err != nil {
+		return m, err
+	}
+	// OGG support is optional, WAV works without it
+	mix.Init(mix.INIT_OGG)
+	if err := mix.OpenAudio(mix.DEFAULT_FREQUENCY, mix.DEFAULT_FORMAT, mix.DEFAULT_CHANNELS, mix.DEFAULT_CHUNKSIZE); err != nil {
+		mix.Quit()
+		sdl.QuitSubSystem(sdl.INIT_AUDIO)
+		return m, err
+	}
+	m.open = true
+	m.allocate()
+	return m, nil
+}
+
+// allocate reserves music channels and gives every
+// effect category its own channel group
+func (m *Manager) allocate() {
+	total := 0
+	for _, c := range Categories {
+		total += ChannelLimits[c]
+	}
+	mix.AllocateChannels(reservedChannels + total)
+	mix.ReserveChannels(reservedChannels)
+	from := reservedChannels
+	for _, c := range Categories {
+		to := from + ChannelLimits[c] - 1
+		mix.GroupChannels(from, to, int(c)+1)
+		from = to + 1
+	}
+}
+
+// Open reports whether audio device is working
+func (m *Manager) Open() bool {
+	return m != nil && m.open
+}
+
+// Load reads effect file once, loading the same sound again does nothing
+func (m *Manager) Load(sound Sound, file string, category Category) error {
+	if !m.Open() {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.chunks[sound]; ok {
+		return nil
+	}
+	chunk, err := mix.LoadWAV(file)
+	if err != nil {
+		return err
+	}
+	m.chunks[sound] = chunk
+	m.categories[sound] = category
+	return nil
+}
+
+// LoadData reads effect from WAV file contents, loading the same sound again does nothing
+func (m *Manager) LoadData(sound Sound, data []byte, category Category) error {
+	if !m.Open() {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.chunks[sound]; ok {
+		return nil
+	}
+	src, err := sdl.RWFromMem(data)
+	if err != nil {
+		return err
+	}
+	chunk, err := mix.LoadWAVRW(src, true)
+	if err != nil {
+		return err
+	}
+	m.chunks[sound] = chunk
+	m.categories[sound] = category
+	return nil
+}
+
+// Loaded reports whether effect is ready to play
+func (m *Manager) Loaded(sound Sound) bool {
+	if !m.Open() {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.chunks[sound]
+	return ok
+}
+
+// LoadDefaults loads DefaultSounds from the dir, missing files are
+// skipped and returned as errors so the game can still run
+func (m *Manager) LoadDefaults(dir string) []error {
+	var errs []error
+	for sound, category := range DefaultSounds {
+		file := ""
+		for _, ext := range Extensions {
+			candidate := filepath.Join(dir, string(sound)+ext)
+			if _, err := os.Stat(candidate); err == nil {
+				file = candidate
+				break
+			}
+		}
+		if file == "" {
+			errs = append(errs, &os.PathError{Op: "load", Path: filepath.Join(dir, string(sound)), Err: os.ErrNotExist})
+			continue
+		}
+		if err := m.Load(sound, file, category); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// SetField sets playfield size, listener starts at its center
+func (m *Manager) SetField(w, h int32) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fieldW, m.fieldH = float64(w), float64(h)
+	m.listenerX, m.listenerY = m.fieldW/2, m.fieldH/2
+}
+
+// SetListener moves the point positional sounds are heard from
+func (m *Manager) SetListener(x, y int32) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listenerX, m.listenerY = float64(x), float64(y)
+}
+
+// Play starts effect on a free channel of its category,
+// returns the channel or -1 when nothing was played
+func (m *Manager) Play(sound Sound) int {
+	if !m.Open() {
+		return -1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	channel := m.play(sound)
+	if channel >= 0 {
+		// Zero angle and distance removes position left by a previous sound
+		mix.SetPosition(channel, 0, 0)
+	}
+	return channel
+}
+
+// PlayAt plays effect panned and attenuated by its position
+// relative to the listener
+func (m *Manager) PlayAt(sound Sound, x, y int32) int {
+	if !m.Open() {
+		return -1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	channel := m.play(sound)
+	if channel >= 0 {
+		angle, distance := m.position(float64(x), float64(y))
+		mix.SetPosition(channel, angle, distance)
+	}
+	return channel
+}
+
+// position returns SDL_mixer angle and distance of the point,
+// left and right of the listener map to 270 and 90 degrees,
+// distance grows towards MaxAttenuation at the playfield diagonal
+func (m *Manager) position(x, y float64) (int16, uint8) {
+	if m.fieldW <= 0 || m.fieldH <= 0 {
+		return 0, 0
+	}
+	dx, dy := x-m.listenerX, y-m.listenerY
+	pan := min(max(dx/(m.fieldW/2), -1), 1)
+	angle := int16(math.Round(pan * 90))
+	if angle < 0 {
+		angle += 360
+	}
+	diagonal := math.Hypot(m.fieldW, m.fieldH)
+	distance := min(math.Hypot(dx, dy)/diagonal, 1) * MaxAttenuation
+	return angle, uint8(distance)
+}
+
+// play starts effect without touching its position, lock must be held
+func (m *Manager) play(sound Sound) int {
+	chunk, ok := m.chunks[sound]
+	if !ok {
+		return -1
+	}
+	category := m.categories[sound]
+	tag := int(category) + 1
+	channel := mix.GroupAvailable(tag)
+	if channel < 0 {
+		// Empty group gives -1, halting that would stop every channel
+		channel = mix.GroupOldest(tag)
+		if channel < 0 {
+			return -1
+		}
+		mix.HaltChannel(channel)
+	}
+	mix.Volume(channel, m.volume(category))
+	channel, err := chunk.Play(channel, 0)
+	if err != nil {
+		return -1
+	}
+	return channel
+}
+
+// volume returns mixer volume of the category
+func (m *Manager) volume(category Category) int {
+	return mix.MAX_VOLUME * m.master * m.effects * m.volumes[category] / (100 * 100 * 100)
+}
+
+// SetVolume changes master, music and effects volumes in percent
+func (m *Manager) SetVolume(master, music, effects int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.master, m.music, m.effects = master, music, effects
+	m.mu.Unlock()
+	if m.Open() {
+		m.Music.apply()
+	}
+}
+
+// SetCategoryVolume changes volume of the category in percent
+func (m *Manager) SetCategoryVolume(category Category, volume int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volumes[category] = volume
+}
+
+// Close stops all sounds and frees resources
+func (m *Manager) Close() {
+	if !m.Open() {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mix.HaltChannel(-1)
+	m.Music.free()
+	for sound, chunk := range m.chunks {
+		chunk.Free()
+		delete(m.chunks, sound)
+	}
+	mix.CloseAudio()
+	mix.Quit()
+	sdl.QuitSubSystem(sdl.INIT_AUDIO)
+	m.open = false
+}
@@ -0,0 +1,227 @@
+package audio
+
+import (
+	"github.com/veandco/go-sdl2/mix"
+)
+
+// Music track names used by scenes
+const (
+	MusicMenu     = "menu"
+	MusicGameplay = "gameplay"
+	MusicBoss     = "boss"
+)
+
+// MaxLayers is how many stems a track may have above its main loop
+const MaxLayers = 3
+
+// Music plays on reserved channels: every deck has main loop channel
+// followed by layer channels, two decks allow crossfading
+const (
+	musicDecks       = 2
+	deckChannels     = 1 + MaxLayers
+	reservedChannels = musicDecks * deckChannels
+)
+
+// Layer is a stem played in sync with the main loop,
+// it fades in when intensity reaches the threshold
+type Layer struct {
+	File      string
+	Threshold float64
+}
+
+// Track is a piece of music, looping point is where the intro ends:
+// intro is played once and then the loop repeats with its layers
+type Track struct {
+	Intro  string
+	Loop   string
+	Layers []Layer
+}
+
+type deck struct {
+	name        string
+	track       *Track
+	level       float64
+	target      float64
+	layerLevels [MaxLayers]float64
+	inIntro     bool
+	playing     bool
+}
+
+// Music crossfades between tracks and mixes their layers,
+// it must be used from the main loop only
+type Music struct {
+	m      *Manager
+	tracks map[string]*Track
+	chunks map[string]*mix.Chunk
+	decks  [musicDecks]deck
+	active int
+	// Current intensity from 0 to 1
+	intensity float64
+	// Fade durations in milliseconds
+	CrossfadeMs, LayerFadeMs float64
+}
+
+func newMusic(m *Manager) *Music {
+	return &Music{
+		m:           m,
+		tracks:      make(map[string]*Track),
+		chunks:      make(map[string]*mix.Chunk),
+		CrossfadeMs: 1500,
+		LayerFadeMs: 800,
+	}
+}
+
+// Register adds track under the name, extra layers above MaxLayers are ignored
+func (mu *Music) Register(name string, track *Track) {
+	if len(track.Layers) > MaxLayers {
+		track.Layers = track.Layers[:MaxLayers]
+	}
+	mu.tracks[name] = track
+}
+
+// chunk loads file once, empty name or bad file gives nil
+func (mu *Music) chunk(file string) *mix.Chunk {
+	if file == "" {
+		return nil
+	}
+	if c, ok := mu.chunks[file]; ok {
+		return c
+	}
+	c, err := mix.LoadWAV(file)
+	if err != nil {
+		c = nil
+	}
+	mu.chunks[file] = c
+	return c
+}
+
+// Play crossfades to the track, playing the same track again does nothing
+func (mu *Music) Play(name string) {
+	if !mu.m.Open() || mu.decks[mu.active].name == name && mu.decks[mu.active].target > 0 {
+		return
+	}
+	track, ok := mu.tracks[name]
+	if !ok {
+		return
+	}
+	mu.decks[mu.active].target = 0
+	mu.active = (mu.active + 1) % musicDecks
+	d := &mu.decks[mu.active]
+	if d.playing && d.name == name {
+		// Track is still fading out, fade it back in where it is
+		d.target = 1
+		mu.apply()
+		return
+	}
+	mu.halt(mu.active)
+	*d = deck{name: name, track: track, target: 1, playing: true}
+	if c := mu.chunk(track.Intro); c != nil {
+		d.inIntro = true
+		c.Play(mu.base(mu.active), 0)
+	} else {
+		mu.startLoop(mu.active)
+	}
+	mu.apply()
+}
+
+// Stop fades out current track
+func (mu *Music) Stop() {
+	mu.decks[mu.active].target = 0
+}
+
+// SetIntensity changes how many layers are heard, 0 gives main loop only
+func (mu *Music) SetIntensity(v float64) {
+	mu.intensity = min(max(v, 0), 1)
+}
+
+// Update advances fades by elapsed milliseconds
+func (mu *Music) Update(elapsedMs float64) {
+	if !mu.m.Open() {
+		return
+	}
+	for i := range mu.decks {
+		d := &mu.decks[i]
+		if !d.playing {
+			continue
+		}
+		// Intro end is the looping point
+		if d.inIntro && mix.Playing(mu.base(i)) == 0 {
+			mu.startLoop(i)
+		}
+		d.level = approach(d.level, d.target, elapsedMs/mu.CrossfadeMs)
+		for l, layer := range d.track.Layers {
+			target := 0.0
+			if mu.intensity >= layer.Threshold {
+				target = 1
+			}
+			d.layerLevels[l] = approach(d.layerLevels[l], target, elapsedMs/mu.LayerFadeMs)
+		}
+		if d.level == 0 && d.target == 0 {
+			mu.halt(i)
+			d.playing = false
+		}
+	}
+	mu.apply()
+}
+
+// startLoop starts main loop and all layers together so they stay in sync
+func (mu *Music) startLoop(i int) {
+	d := &mu.decks[i]
+	d.inIntro = false
+	base := mu.base(i)
+	if c := mu.chunk(d.track.Loop); c != nil {
+		mix.Volume(base, 0)
+		c.Play(base, -1)
+	}
+	for l, layer := range d.track.Layers {
+		if c := mu.chunk(layer.File); c != nil {
+			mix.Volume(base+1+l, 0)
+			c.Play(base+1+l, -1)
+		}
+	}
+}
+
+// apply sets channel volumes from deck and layer levels
+func (mu *Music) apply() {
+	volume := float64(mix.MAX_VOLUME*mu.m.master*mu.m.music) / (100 * 100)
+	for i := range mu.decks {
+		d := &mu.decks[i]
+		if !d.playing {
+			continue
+		}
+		base := mu.base(i)
+		mix.Volume(base, int(volume*d.level))
+		for l := range d.track.Layers {
+			mix.Volume(base+1+l, int(volume*d.level*d.layerLevels[l]))
+		}
+	}
+}
+
+func (mu *Music) halt(i int) {
+	base := mu.base(i)
+	for ch := base; ch < base+deckChannels; ch++ {
+		mix.HaltChannel(ch)
+	}
+}
+
+func (mu *Music) base(i int) int {
+	return i * deckChannels
+}
+
+// free releases loaded chunks, channels must be halted before
+func (mu *Music) free() {
+	for file, c := range mu.chunks {
+		if c != nil {
+			c.Free()
+		}
+		delete(mu.chunks, file)
+	}
+}
+
+// approach moves value towards target by step
+func approach(value, target, step float64) float64 {
+	if value < target {
+		return min(value+step, target)
+	}
+	return max(value-step, target)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"sdl_learn/logger"
+	"sdl_learn/render"
+	"sdl_learn/render/opengl"
+	"sdl_learn/render/post"
+	"sdl_learn/settings"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// OpenGL backend state, glDevice is nil with the SDL backend.
+// With OpenGL, game object sprites are drawn by the device and
+// everything drawn with rend, like text and menus, goes to a
+// transparent overlay drawn over the sprites. Particles go there
+// too, the overlay is uploaded premultiplied so additive ones still
+// brighten the frame where the overlay is empty, but they add to
+// the 8 bit overlay and not to the GL sprites under them, so glow
+// over menus or text saturates there.
+var (
+	glDevice       *opengl.Device
+	overlay        *sdl.Surface
+	overlayTexture *opengl.Texture
+	starsTexture   *opengl.Texture
+	// Post effects of the SDL backend, OpenGL device applies its own
+	postChain *post.Chain
+)
+
+// windowFlags prepares the backend chosen in settings before
+// the window is created and returns flags the window needs
+func windowFlags() uint32 {
+	if settings.Current.Window.Renderer != settings.RendererOpenGL {
+		return 0
+	}
+	if err := opengl.SetAttributes(); err != nil {
+		logger.Error("unable to request OpenGL context: %s", err.Error())
+		return 0
+	}
+	return sdl.WINDOW_OPENGL
+}
+
+// initRenderer creates renderers for the window, OpenGL falls back to SDL
+func initRenderer() {
+	if win.GetFlags()&sdl.WINDOW_OPENGL != 0 {
+		err := initOpenGL()
+		if err == nil {
+			return
+		}
+		logger.Error("unable to start OpenGL, using SDL: %s", err.Error())
+		freeRenderer()
+	}
+	rend, err = sdl.CreateRenderer(win, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC|sdl.RENDERER_TARGETTEXTURE)
+	perror(err)
+	screen = render.NewSDL(rend)
+}
+
+// initPost builds post-processing chain from settings,
+// effects which fail to start are turned off
+func initPost() {
+	graphics := settings.Current.Graphics
+	if glDevice != nil {
+		if err := glDevice.SetPostEffects(graphics.PostEffects, graphics.LUT); err != nil {
+			logger.Error("unable to start post effects: %s", err.Error())
+		}
+		return
+	}
+	postChain, err = post.New(rend, WindowWidth, WindowHeight, graphics.PostEffects)
+	if err != nil {
+		logger.Error("unable to start post effects: %s", err.Error())
+		postChain = nil
+		return
+	}
+	for _, name := range postChain.Skipped {
+		logger.Error("post effect %s needs OpenGL renderer", name)
+	}
+}
+
+func initOpenGL() error {
+	glDevice, err = opengl.New(win, WindowWidth, WindowHeight, true)
+	if err != nil {
+		return err
+	}
+	overlay, err = sdl.CreateRGBSurfaceWithFormat(0, WindowWidth, WindowHeight, 32, sdl.PIXELFORMAT_ABGR8888)
+	if err != nil {
+		return err
+	}
+	rend, err = sdl.CreateSoftwareRenderer(overlay)
+	if err != nil {
+		return err
+	}
+	overlayTexture = glDevice.NewTexture(WindowWidth, WindowHeight)
+	// SDL blending onto transparent black gives premultiplied colors
+	overlayTexture.Premultiplied = true
+	starsTexture = glDevice.NewTexture(WindowWidth, WindowHeight)
+	screen = glDevice
+	return nil
+}
+
+// freeRenderer releases what initRenderer created
+func freeRenderer() {
+	if postChain != nil {
+		postChain.Free()
+		postChain = nil
+	}
+	if rend != nil {
+		rend.Destroy()
+		rend = nil
+	}
+	if overlay != nil {
+		overlay.Free()
+		overlay = nil
+	}
+	if glDevice != nil {
+		glDevice.Destroy()
+		glDevice = nil
+	}
+}
+
+// backgroundRenderer returns renderer for the star field texture,
+// OpenGL uploads star pixels itself
+func backgroundRenderer() *sdl.Renderer {
+	if glDevice != nil {
+		return nil
+	}
+	return rend
+}
+
+// drawFrame draws background and scenes and shows the frame
+func drawFrame() {
+	if glDevice == nil {
+		if postChain != nil {
+			postChain.Begin(rend)
+		}
+		clearView(sdl.Color{R: 0, G: 0, B: 0, A: 255})
+		stars.Draw(rend, &sdl.Rect{W: WindowWidth, H: WindowHeight})
+		scenes.Draw(rend)
+		if postChain != nil {
+			postChain.End(rend)
+		}
+		captureFrame()
+		rend.Present()
+		return
+	}
+
+	glDevice.Clear(sdl.Color{R: 0, G: 0, B: 0, A: 255})
+	starsTexture.Update(unsafe.Pointer(&stars.Render()[0]), WindowWidth*4)
+	glDevice.DrawSprite(starsTexture, nil, nil)
+
+	rend.SetDrawColor(0, 0, 0, 0)
+	rend.Clear()
+	scenes.Draw(rend)
+	// Present flushes software renderer into the surface
+	rend.Present()
+	if err := overlayTexture.UpdateSurface(overlay); err != nil {
+		logger.Error("unable to update overlay: %s", err.Error())
+	}
+	glDevice.DrawSprite(overlayTexture, nil, nil)
+	captureFrame()
+	glDevice.Present()
+}
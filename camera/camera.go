@@ -0,0 +1,184 @@
+package camera
+
+import (
+	"math"
+	"sync"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Defaults of a new camera
+const (
+	DefaultSmoothing   = 5
+	DefaultMaxShake    = 16
+	DefaultTraumaDecay = 1.2
+)
+
+// Camera converts world coordinates to the screen, X and Y are
+// the world point shown in the middle of the viewport
+type Camera struct {
+	X, Y float64
+	// Scale of the world, 2 shows it twice as big
+	Zoom float64
+	// Size of the viewport in screen pixels
+	ViewW, ViewH float64
+	// World area the camera never looks out of, empty for no limits
+	Bounds sdl.FRect
+	// How fast camera catches up with the target, per second
+	Smoothing float64
+	// Shake offset in pixels at full trauma
+	MaxShake float64
+	// Trauma lost per second
+	TraumaDecay float64
+
+	target func() (x, y float64)
+	// Trauma is set from game object goroutines
+	mu      sync.Mutex
+	trauma  float64
+	time    float64
+	offsetX float64
+	offsetY float64
+}
+
+// New creates camera looking at the middle of the viewport
+func New(viewW, viewH int32) *Camera {
+	return &Camera{
+		X:           float64(viewW) / 2,
+		Y:           float64(viewH) / 2,
+		Zoom:        1,
+		ViewW:       float64(viewW),
+		ViewH:       float64(viewH),
+		Smoothing:   DefaultSmoothing,
+		MaxShake:    DefaultMaxShake,
+		TraumaDecay: DefaultTraumaDecay,
+	}
+}
+
+// Follow makes camera move towards target, nil stops following
+func (c *Camera) Follow(target func() (x, y float64)) {
+	c.target = target
+}
+
+// LookAt moves camera to the point at once
+func (c *Camera) LookAt(x, y float64) {
+	c.X, c.Y = x, y
+	c.clamp()
+}
+
+// AddTrauma makes camera shake, trauma is kept in 0..1,
+// it is safe to call from other goroutines
+func (c *Camera) AddTrauma(amount float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trauma = math.Min(math.Max(c.trauma+amount, 0), 1)
+}
+
+// Trauma returns current trauma
+func (c *Camera) Trauma() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.trauma
+}
+
+// Reset stops shaking
+func (c *Camera) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trauma = 0
+	c.offsetX, c.offsetY = 0, 0
+}
+
+// Update follows the target and shakes, dt is in seconds
+func (c *Camera) Update(dt float64) {
+	if c.target != nil {
+		x, y := c.target()
+		// Frame rate independent exponential smoothing
+		k := 1 - math.Exp(-c.Smoothing*dt)
+		c.X += (x - c.X) * k
+		c.Y += (y - c.Y) * k
+	}
+	c.clamp()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.time += dt
+	c.trauma = math.Max(c.trauma-c.TraumaDecay*dt, 0)
+	// Shake grows with square of trauma so small hits are subtle,
+	// sums of sines give smooth noise without extra state
+	shake := c.MaxShake * c.trauma * c.trauma
+	t := c.time
+	c.offsetX = shake * (math.Sin(t*37) + math.Sin(t*59+1.3)) / 2
+	c.offsetY = shake * (math.Sin(t*43+2.1) + math.Sin(t*71+0.7)) / 2
+}
+
+// clamp keeps view inside the bounds
+func (c *Camera) clamp() {
+	if c.Bounds.W <= 0 || c.Bounds.H <= 0 {
+		return
+	}
+	halfW, halfH := c.ViewW/2/c.Zoom, c.ViewH/2/c.Zoom
+	c.X = clampAxis(c.X, float64(c.Bounds.X), float64(c.Bounds.W), halfW)
+	c.Y = clampAxis(c.Y, float64(c.Bounds.Y), float64(c.Bounds.H), halfH)
+}
+
+// clampAxis centers view on the bounds when they are smaller than view
+func clampAxis(v, min, size, half float64) float64 {
+	if size <= half*2 {
+		return min + size/2
+	}
+	return math.Min(math.Max(v, min+half), min+size-half)
+}
+
+// offset returns current shake
+func (c *Camera) offset() (float64, float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offsetX, c.offsetY
+}
+
+// ToScreen converts world point to the screen
+func (c *Camera) ToScreen(x, y float64) (float64, float64) {
+	ox, oy := c.offset()
+	return (x-c.X)*c.Zoom + c.ViewW/2 + ox, (y-c.Y)*c.Zoom + c.ViewH/2 + oy
+}
+
+// ToWorld converts screen point to the world
+func (c *Camera) ToWorld(x, y float64) (float64, float64) {
+	ox, oy := c.offset()
+	return (x-ox-c.ViewW/2)/c.Zoom + c.X, (y-oy-c.ViewH/2)/c.Zoom + c.Y
+}
+
+// Rect converts world rect to the screen
+func (c *Camera) Rect(r sdl.Rect) sdl.Rect {
+	x, y := c.ToScreen(float64(r.X), float64(r.Y))
+	return sdl.Rect{
+		X: int32(math.Round(x)),
+		Y: int32(math.Round(y)),
+		W: int32(math.Round(float64(r.W) * c.Zoom)),
+		H: int32(math.Round(float64(r.H) * c.Zoom)),
+	}
+}
+
+// FRect converts world rect to the screen
+func (c *Camera) FRect(r sdl.FRect) sdl.FRect {
+	x, y := c.ToScreen(float64(r.X), float64(r.Y))
+	return sdl.FRect{
+		X: float32(x),
+		Y: float32(y),
+		W: float32(float64(r.W) * c.Zoom),
+		H: float32(float64(r.H) * c.Zoom),
+	}
+}
+
+// View returns world area seen by the camera, shake excluded
+func (c *Camera) View() sdl.FRect {
+	w, h := c.ViewW/c.Zoom, c.ViewH/c.Zoom
+	return sdl.FRect{X: float32(c.X - w/2), Y: float32(c.Y - h/2), W: float32(w), H: float32(h)}
+}
+
+// Visible reports whether world rect is at least partly on the screen
+func (c *Camera) Visible(r sdl.Rect) bool {
+	v := c.View()
+	return float32(r.X+r.W) > v.X && float32(r.X) < v.X+v.W &&
+		float32(r.Y+r.H) > v.Y && float32(r.Y) < v.Y+v.H
+}
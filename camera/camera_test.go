@@ -0,0 +1,131 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestFollow(t *testing.T) {
+	c := New(100, 100)
+	c.Follow(func() (float64, float64) { return 150, 50 })
+	c.Update(0.1)
+	want := 50 + 100*(1-math.Exp(-DefaultSmoothing*0.1))
+	if !near(c.X, want) || c.Y != 50 {
+		t.Errorf("after 0.1s camera at %v,%v want %v,50", c.X, c.Y, want)
+	}
+
+	// Two short frames move as far as one long frame
+	split := New(100, 100)
+	split.Follow(func() (float64, float64) { return 150, 50 })
+	split.Update(0.05)
+	split.Update(0.05)
+	if !near(split.X, c.X) {
+		t.Errorf("two 0.05s frames reached %v, one 0.1s frame %v", split.X, c.X)
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Update(0.1)
+	}
+	if !near(c.X, 150) {
+		t.Errorf("camera settled at %v want 150", c.X)
+	}
+	c.Follow(nil)
+	c.Update(0.1)
+	if !near(c.X, 150) {
+		t.Errorf("camera without target moved to %v", c.X)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	c := New(100, 100)
+	c.Bounds = sdl.FRect{X: 0, Y: 0, W: 400, H: 300}
+	c.LookAt(-50, 1000)
+	if c.X != 50 || c.Y != 250 {
+		t.Errorf("clamped to %v,%v want 50,250", c.X, c.Y)
+	}
+	c.LookAt(200, 150)
+	if c.X != 200 || c.Y != 150 {
+		t.Errorf("point inside bounds moved to %v,%v", c.X, c.Y)
+	}
+
+	// Zoomed view is smaller so it gets closer to the edges
+	c.Zoom = 2
+	c.LookAt(0, 0)
+	if c.X != 25 || c.Y != 25 {
+		t.Errorf("zoomed clamp %v,%v want 25,25", c.X, c.Y)
+	}
+
+	// Bounds smaller than the view are centered
+	c.Zoom = 1
+	c.Bounds = sdl.FRect{X: 10, Y: 20, W: 60, H: 400}
+	c.Follow(func() (float64, float64) { return 0, 0 })
+	c.Update(1)
+	if c.X != 40 || c.Y != 70 {
+		t.Errorf("narrow bounds %v,%v want 40,70", c.X, c.Y)
+	}
+}
+
+func TestZoom(t *testing.T) {
+	c := New(200, 100)
+	c.LookAt(50, 50)
+	c.Zoom = 2
+	if x, y := c.ToScreen(50, 50); x != 100 || y != 50 {
+		t.Errorf("camera point on screen at %v,%v want the middle", x, y)
+	}
+	if x, y := c.ToScreen(60, 40); x != 120 || y != 30 {
+		t.Errorf("zoomed point at %v,%v want 120,30", x, y)
+	}
+	if x, y := c.ToWorld(c.ToScreen(12.5, -7)); !near(x, 12.5) || !near(y, -7) {
+		t.Errorf("round trip gave %v,%v", x, y)
+	}
+	if r := c.Rect(sdl.Rect{X: 50, Y: 50, W: 10, H: 5}); r != (sdl.Rect{X: 100, Y: 50, W: 20, H: 10}) {
+		t.Errorf("zoomed rect %v", r)
+	}
+	if v := c.View(); v != (sdl.FRect{X: 0, Y: 25, W: 100, H: 50}) {
+		t.Errorf("zoomed view %v", v)
+	}
+	if !c.Visible(sdl.Rect{X: 95, Y: 70, W: 10, H: 10}) || c.Visible(sdl.Rect{X: 100, Y: 70, W: 10, H: 10}) {
+		t.Error("visibility at the view edge")
+	}
+}
+
+func TestTrauma(t *testing.T) {
+	c := New(100, 100)
+	c.AddTrauma(0.7)
+	c.AddTrauma(0.7)
+	if c.Trauma() != 1 {
+		t.Fatalf("trauma %v not clamped to 1", c.Trauma())
+	}
+	c.Update(0.5)
+	if got := c.Trauma(); !near(got, 1-DefaultTraumaDecay*0.5) {
+		t.Errorf("trauma after 0.5s %v", got)
+	}
+	if ox, oy := c.offset(); ox == 0 && oy == 0 {
+		t.Error("camera with trauma does not shake")
+	}
+	// Shake never exceeds MaxShake
+	for i := 0; i < 20; i++ {
+		c.AddTrauma(1)
+		c.Update(0.01)
+		if ox, oy := c.offset(); math.Abs(ox) > c.MaxShake || math.Abs(oy) > c.MaxShake {
+			t.Fatalf("shake %v,%v over %v", ox, oy, c.MaxShake)
+		}
+	}
+
+	c.Update(1)
+	if ox, oy := c.offset(); c.Trauma() != 0 || ox != 0 || oy != 0 {
+		t.Errorf("trauma %v shake %v,%v after decay", c.Trauma(), ox, oy)
+	}
+	c.AddTrauma(0.5)
+	c.Update(0.1)
+	c.Reset()
+	if ox, oy := c.offset(); c.Trauma() != 0 || ox != 0 || oy != 0 {
+		t.Errorf("trauma %v shake %v,%v after reset", c.Trauma(), ox, oy)
+	}
+}
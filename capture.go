@@ -0,0 +1,133 @@
+package main
+
+import (
+	"image"
+	"path/filepath"
+	"sdl_learn/inputs"
+	"sdl_learn/logger"
+	"sdl_learn/recorder"
+	"sdl_learn/screenshot"
+	"sdl_learn/settings"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Capture keys working in every scene: screenshot of the next frame,
+// start and stop of a recording and saving of the last seconds
+const (
+	screenshotKey = sdl.SCANCODE_F12
+	recordKey     = sdl.SCANCODE_F9
+	replayKey     = sdl.SCANCODE_F10
+)
+
+// Screenshots and recordings are saved here
+const (
+	ScreenshotDir = "screenshots"
+	RecordingDir  = "recordings"
+)
+
+var (
+	// Set by the key, the frame is saved when drawn
+	screenshotRequested bool
+	// Segment recorded with the record key
+	segment *recorder.Recorder
+	// Last seconds, nil when replay is off in settings
+	replay *recorder.Recorder
+)
+
+// initRecorders creates recorders from settings
+func initRecorders() {
+	rec := settings.Current.Recording
+	fps := int(settings.Current.Window.FPS)
+	options := recorder.Options{FPS: fps, Skip: rec.FrameSkip, Scale: rec.Scale}
+	options.MaxFrames = recorder.FramesFor(float64(rec.MaxSeconds), fps, rec.FrameSkip)
+	segment = recorder.New(options)
+	if rec.ReplaySeconds > 0 {
+		options.MaxFrames = recorder.FramesFor(float64(rec.ReplaySeconds), fps, rec.FrameSkip)
+		replay = recorder.New(options)
+		replay.Replay = true
+	}
+}
+
+// handleCaptureEvent reacts to the capture keys,
+// returns true when event was consumed
+func handleCaptureEvent(e sdl.Event) bool {
+	switch {
+	case inputs.Pressed(e, screenshotKey):
+		screenshotRequested = true
+	case inputs.Pressed(e, recordKey):
+		if !segment.Recording() {
+			segment.Start()
+			logger.Info("recording started")
+		} else {
+			exportRecording(segment, segment.Stop())
+		}
+	case inputs.Pressed(e, replayKey) && replay != nil:
+		exportRecording(replay, replay.Frames())
+	default:
+		return false
+	}
+	return true
+}
+
+// exportRecording writes frames of the recorder in the background
+func exportRecording(r *recorder.Recorder, frames []*image.NRGBA) {
+	delays := r.Delays(len(frames))
+	format := settings.Current.Recording.Format
+	name := filepath.Join(RecordingDir, "recording-"+time.Now().Format("20060102-150405"))
+	go func() {
+		path, err := recorder.Export(format, name, frames, delays)
+		if err != nil {
+			logger.Error("unable to save recording: %s", err.Error())
+			return
+		}
+		logger.Info("recording of %d frames saved to %s", len(frames), path)
+	}()
+}
+
+// readFrame returns the drawn frame, call it before present
+func readFrame() (*image.NRGBA, error) {
+	if glDevice != nil {
+		glDevice.Flush()
+		img := glDevice.ReadPixels()
+		screenshot.Opaque(img)
+		return img, nil
+	}
+	return screenshot.Capture(rend)
+}
+
+// captureFrame saves screenshot when it was requested and passes
+// the frame to recorders which want it
+func captureFrame() {
+	wantsSegment := segment.Wants()
+	wantsReplay := replay != nil && replay.Wants()
+	if !screenshotRequested && !wantsSegment && !wantsReplay {
+		return
+	}
+	img, err := readFrame()
+	if err != nil {
+		logger.Error("unable to read frame: %s", err.Error())
+		return
+	}
+	if wantsSegment {
+		segment.Add(img)
+		// Segment ends by itself when it is full
+		if segment.Len() == segment.MaxFrames {
+			exportRecording(segment, segment.Stop())
+		}
+	}
+	if wantsReplay {
+		replay.Add(img)
+	}
+	if !screenshotRequested {
+		return
+	}
+	screenshotRequested = false
+	path := screenshot.Path(ScreenshotDir, time.Now())
+	if err := screenshot.Save(img, path); err != nil {
+		logger.Error("unable to save screenshot: %s", err.Error())
+		return
+	}
+	logger.Info("screenshot saved to %s", path)
+}
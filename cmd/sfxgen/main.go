@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sdl_learn/sfxr"
+)
+
+var presets = map[string]func(*rand.Rand) sfxr.Params{
+	"laser":     sfxr.Laser,
+	"explosion": sfxr.Explosion,
+	"pickup":    sfxr.Pickup,
+	"hit":       sfxr.Hit,
+}
+
+// Writes generated effect to a WAV file, e.g.
+// go run ./cmd/sfxgen -preset laser -seed 7 -out assets/sfx/shoot.wav
+func main() {
+	preset := flag.String("preset", "laser", "laser, explosion, pickup or hit")
+	seed := flag.Int64("seed", 1, "seed of the preset and noise")
+	mutations := flag.Int("mutate", 0, "number of random mutations applied")
+	out := flag.String("out", "sfx.wav", "output file")
+	flag.Parse()
+
+	kind, ok := presets[*preset]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown preset %q\n", *preset)
+		os.Exit(2)
+	}
+	rng := rand.New(rand.NewSource(*seed))
+	params := kind(rng)
+	for i := 0; i < *mutations; i++ {
+		params = params.Mutate(rng, 0.05)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if err := sfxr.WriteWAV(f, params.Synthesize(*seed)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
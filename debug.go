@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sdl_learn/debug"
+	"sdl_learn/gobject"
+	"sdl_learn/render"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Key toggling the debug overlay
+const debugKey = sdl.SCANCODE_F3
+
+// Debug overlay, hidden until debugKey is pressed
+var debugOverlay *debug.Overlay
+
+// initDebug creates debug overlay with its own smaller font
+func initDebug() (free func()) {
+	debugFont, freeFont, err := loadFont(rend, DebugFontSize)
+	perror(err)
+	debugOverlay = debug.New(debugFont)
+	return freeFont
+}
+
+// debugObjects lists player, enemies and bullets of the manager
+func debugObjects(m *gobject.Manager) []*gobject.Gobject {
+	objects := make([]*gobject.Gobject, 0, 1+len(m.Enemies)+len(m.Bullets))
+	objects = append(objects, m.PlayerObj)
+	for _, val := range m.Enemies {
+		objects = append(objects, val)
+	}
+	for _, val := range m.Bullets {
+		objects = append(objects, val)
+	}
+	return objects
+}
+
+// debugStats collects counters shown by the overlay
+func debugStats(m *gobject.Manager) debug.Stats {
+	stats := debug.Stats{
+		Enemies:  len(m.Enemies),
+		Bullets:  len(m.Bullets),
+		Textures: -1,
+		Queue:    queue.Stats,
+	}
+	if c, ok := screen.(render.TextureCounter); ok {
+		stats.Textures = c.TextureCount()
+	}
+	return stats
+}
@@ -0,0 +1,256 @@
+package debug
+
+import (
+	"fmt"
+	"math"
+	"sdl_learn/gobject"
+	"sdl_learn/render"
+	"sdl_learn/text"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Frame graph settings
+const (
+	// Number of frames shown in the graph
+	GraphFrames = 120
+	// Graph height in pixels and the frame time it stands for
+	GraphHeight int32   = 60
+	GraphMaxMs  float64 = 50
+	// Reference line of the 60 FPS frame
+	TargetMs float64 = 1000.0 / 60
+	// Seconds of velocity shown by the movement line
+	VelocityScale = 0.25
+	// How much of the new velocity is taken each frame
+	VelocitySmoothing = 0.3
+	// Margin from the screen edges in pixels
+	Margin int32 = 10
+)
+
+// Colors of the overlay
+var (
+	MovingColor   = sdl.Color{R: 0, G: 255, B: 0, A: 255}
+	StoppedColor  = sdl.Color{R: 255, G: 0, B: 0, A: 255}
+	VelocityColor = sdl.Color{R: 255, G: 220, B: 0, A: 255}
+	TextColor     = sdl.Color{R: 255, G: 255, B: 255, A: 255}
+	GraphColor    = sdl.Color{R: 0, G: 200, B: 255, A: 255}
+	SlowColor     = sdl.Color{R: 255, G: 80, B: 80, A: 255}
+	TargetColor   = sdl.Color{R: 255, G: 255, B: 255, A: 160}
+	BackdropColor = sdl.Color{R: 0, G: 0, B: 0, A: 160}
+)
+
+// Stats shown under the frame graph
+type Stats struct {
+	Enemies, Bullets int
+	// Live textures, -1 when renderer does not count them
+	Textures int
+	Queue    render.Stats
+}
+
+// Overlay draws collision rects, ids, movement and frame times
+type Overlay struct {
+	Visible bool
+	Font    text.Font
+	// Frame times in milliseconds, ring buffer
+	frames [GraphFrames]float64
+	next   int
+	count  int
+	// Last positions and smoothed velocities in pixels per second by Id
+	last     map[string]sdl.Point
+	velocity map[string]sdl.FPoint
+}
+
+// New creates hidden overlay drawing text with the font
+func New(font text.Font) *Overlay {
+	return &Overlay{
+		Font:     font,
+		last:     make(map[string]sdl.Point),
+		velocity: make(map[string]sdl.FPoint),
+	}
+}
+
+// Toggle shows or hides the overlay
+func (o *Overlay) Toggle() {
+	o.Visible = !o.Visible
+}
+
+// Frame records frame time and object movement since the previous frame
+func (o *Overlay) Frame(ms float64, objects []*gobject.Gobject) {
+	o.frames[o.next] = ms
+	o.next = (o.next + 1) % GraphFrames
+	if o.count < GraphFrames {
+		o.count++
+	}
+
+	seen := make(map[string]bool, len(objects))
+	for _, gob := range objects {
+		seen[gob.Id] = true
+		pos := sdl.Point{X: gob.X, Y: gob.Y}
+		last, ok := o.last[gob.Id]
+		o.last[gob.Id] = pos
+		if !ok || ms <= 0 {
+			continue
+		}
+		dt := float32(ms / 1000)
+		v := o.velocity[gob.Id]
+		v.X += (float32(pos.X-last.X)/dt - v.X) * VelocitySmoothing
+		v.Y += (float32(pos.Y-last.Y)/dt - v.Y) * VelocitySmoothing
+		o.velocity[gob.Id] = v
+	}
+	// Forget objects which are gone
+	for id := range o.last {
+		if !seen[id] {
+			delete(o.last, id)
+			delete(o.velocity, id)
+		}
+	}
+}
+
+// Velocity returns smoothed velocity of the object in pixels per second
+func (o *Overlay) Velocity(id string) sdl.FPoint {
+	return o.velocity[id]
+}
+
+// Reset forgets frame times and movement
+func (o *Overlay) Reset() {
+	o.next, o.count = 0, 0
+	clear(o.last)
+	clear(o.velocity)
+}
+
+// Draw renders overlay if visible, objects are in world coordinates
+// converted with the view, graph and stats are drawn in screen ones
+func (o *Overlay) Draw(r *sdl.Renderer, view render.Transform, objects []*gobject.Gobject, stats Stats) {
+	if !o.Visible {
+		return
+	}
+	var mode sdl.BlendMode
+	r.GetDrawBlendMode(&mode)
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	defer r.SetDrawBlendMode(mode)
+
+	for _, gob := range objects {
+		o.drawObject(r, view, gob)
+	}
+	o.drawGraph(r, stats)
+}
+
+// drawObject renders collision rect, movement line and labels of one object
+func (o *Overlay) drawObject(r *sdl.Renderer, view render.Transform, gob *gobject.Gobject) {
+	world := gob.Rect()
+	rect := view.Rect(world)
+	color := StoppedColor
+	if gob.IsMoving {
+		color = MovingColor
+	}
+	setColor(r, color)
+	r.DrawRect(&rect)
+
+	v := o.velocity[gob.Id]
+	if v.X != 0 || v.Y != 0 {
+		cx, cy := world.X+world.W/2, world.Y+world.H/2
+		from := view.Rect(sdl.Rect{X: cx, Y: cy})
+		to := view.Rect(sdl.Rect{
+			X: cx + int32(v.X*VelocityScale),
+			Y: cy + int32(v.Y*VelocityScale),
+		})
+		setColor(r, VelocityColor)
+		r.DrawLine(from.X, from.Y, to.X, to.Y)
+	}
+
+	if o.Font == nil {
+		return
+	}
+	state := "stopped"
+	if gob.IsMoving {
+		state = "moving"
+	}
+	if gob.IsShoot {
+		state += " shoot"
+	}
+	y := rect.Y - 2*o.Font.LineHeight()
+	if y < 0 {
+		y = rect.Y + rect.H
+	}
+	o.Font.Draw(r, gob.Id, rect.X, y, color)
+	o.Font.Draw(r, fmt.Sprintf("%s %.0f,%.0f", state, v.X, v.Y), rect.X, y+o.Font.LineHeight(), color)
+}
+
+// drawGraph renders frame times and counters in the bottom left corner
+func (o *Overlay) drawGraph(r *sdl.Renderer, stats Stats) {
+	_, screenH, err := r.GetOutputSize()
+	if err != nil {
+		return
+	}
+	if vp := r.GetViewport(); vp.H > 0 {
+		screenH = vp.H
+	}
+
+	lines := []string{
+		fmt.Sprintf("frame %.1f ms avg %.1f ms", o.latest(), o.average()),
+		fmt.Sprintf("enemies %d bullets %d", stats.Enemies, stats.Bullets),
+		fmt.Sprintf("sprites %d draws %d switches %d", stats.Queue.Sprites, stats.Queue.DrawCalls, stats.Queue.TextureSwitches),
+	}
+	if stats.Textures >= 0 {
+		lines = append(lines, fmt.Sprintf("textures %d", stats.Textures))
+	}
+	var lineH int32
+	if o.Font != nil {
+		lineH = o.Font.LineHeight()
+	}
+
+	graphW := int32(GraphFrames * 2)
+	boxH := GraphHeight + int32(len(lines))*lineH + Margin
+	box := sdl.Rect{X: Margin / 2, Y: screenH - boxH - Margin/2, W: graphW + Margin, H: boxH}
+	setColor(r, BackdropColor)
+	r.FillRect(&box)
+
+	bottom := screenH - Margin
+	left := Margin
+	// Oldest frame on the left
+	for i := 0; i < o.count; i++ {
+		ms := o.frames[(o.next-o.count+i+GraphFrames)%GraphFrames]
+		h := int32(min(ms, GraphMaxMs) / GraphMaxMs * float64(GraphHeight))
+		color := GraphColor
+		if ms > TargetMs {
+			color = SlowColor
+		}
+		setColor(r, color)
+		r.FillRect(&sdl.Rect{X: left + int32(i)*2, Y: bottom - h, W: 2, H: h})
+	}
+	target := bottom - int32(math.Round(TargetMs/GraphMaxMs*float64(GraphHeight)))
+	setColor(r, TargetColor)
+	r.DrawLine(left, target, left+graphW, target)
+
+	if o.Font == nil {
+		return
+	}
+	y := bottom - GraphHeight - int32(len(lines))*lineH
+	for i, line := range lines {
+		o.Font.Draw(r, line, left, y+int32(i)*lineH, TextColor)
+	}
+}
+
+// latest returns time of the latest frame
+func (o *Overlay) latest() float64 {
+	if o.count == 0 {
+		return 0
+	}
+	return o.frames[(o.next-1+GraphFrames)%GraphFrames]
+}
+
+// average returns mean frame time of the graph
+func (o *Overlay) average() float64 {
+	if o.count == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < o.count; i++ {
+		sum += o.frames[(o.next-1-i+GraphFrames)%GraphFrames]
+	}
+	return sum / float64(o.count)
+}
+
+func setColor(r *sdl.Renderer, c sdl.Color) {
+	r.SetDrawColor(c.R, c.G, c.B, c.A)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"sdl_learn/inputs"
+	"sdl_learn/logger"
+	"sdl_learn/settings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Key switching between window and fullscreen in every scene
+const fullscreenKey = sdl.SCANCODE_F11
+
+// Color of the bars around the playfield
+var letterboxColor = sdl.Color{R: 0, G: 0, B: 0, A: 255}
+
+// setupView scales logical playfield to the window,
+// with integer scaling the playfield keeps whole pixel sizes
+func setupView() {
+	rend.SetLogicalSize(WindowWidth, WindowHeight)
+	rend.SetIntegerScale(settings.Current.Window.IntegerScale)
+	if glDevice != nil {
+		glDevice.IntegerScale = settings.Current.Window.IntegerScale
+	}
+}
+
+// setFullscreen switches window mode, fullscreen uses desktop
+// resolution and relies on the logical size for scaling
+func setFullscreen(on bool) {
+	var flags uint32
+	if on {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	if err := win.SetFullscreen(flags); err != nil {
+		logger.Error("unable to change window mode: %s", err.Error())
+		return
+	}
+	settings.Current.Window.Fullscreen = on
+	setupView()
+}
+
+// handleDisplayEvent reacts to window size changes and fullscreen key,
+// returns true when event was consumed
+func handleDisplayEvent(e sdl.Event) bool {
+	if inputs.Pressed(e, fullscreenKey) {
+		setFullscreen(!settings.Current.Window.Fullscreen)
+		return true
+	}
+	we, ok := e.(*sdl.WindowEvent)
+	if !ok || we.Event != sdl.WINDOWEVENT_SIZE_CHANGED {
+		return false
+	}
+	// Remember windowed size for the next start
+	if !settings.Current.Window.Fullscreen {
+		settings.Current.Window.Width = we.Data1
+		settings.Current.Window.Height = we.Data2
+	}
+	setupView()
+	return true
+}
+
+// clearView fills bars with letterbox color and playfield with the background
+func clearView(background sdl.Color) {
+	rend.SetDrawColor(letterboxColor.R, letterboxColor.G, letterboxColor.B, letterboxColor.A)
+	rend.Clear()
+	rend.SetDrawColor(background.R, background.G, background.B, background.A)
+	rend.FillRect(&sdl.Rect{W: WindowWidth, H: WindowHeight})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"sdl_learn/gobject"
+	"sdl_learn/particles"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Most particles alive at once
+const maxParticles = 4096
+
+// Camera trauma added by game events
+const (
+	playerHitTrauma       = 0.4
+	explosionTrauma       = 0.3
+	playerExplosionTrauma = 0.8
+)
+
+// Particle effects of the game objects
+var (
+	effects         *particles.System
+	effectsRenderer *particles.Renderer
+)
+
+// Engine exhaust of the battleship
+var exhaustConfig = particles.Config{
+	Rate:     90,
+	LifeMin:  0.15,
+	LifeMax:  0.35,
+	SpeedMin: 120,
+	SpeedMax: 220,
+	Angle:    math.Pi / 2,
+	Spread:   0.25,
+	Size:     particles.Line(14, 4),
+	Alpha:    particles.Line(0.9, 0),
+	Color: particles.ColorCurve{
+		{T: 0, C: color.NRGBA{R: 160, G: 220, B: 255}},
+		{T: 0.4, C: color.NRGBA{R: 60, G: 120, B: 255}},
+		{T: 1, C: color.NRGBA{R: 40, G: 20, B: 120}},
+	},
+	Additive: true,
+}
+
+// Fireball of a destroyed object
+var explosionConfig = particles.Config{
+	Burst:    80,
+	LifeMin:  0.3,
+	LifeMax:  0.8,
+	SpeedMin: 40,
+	SpeedMax: 260,
+	Spread:   math.Pi,
+	Size:     particles.Curve{{T: 0, V: 10}, {T: 0.2, V: 28}, {T: 1, V: 6}},
+	Alpha:    particles.Line(1, 0),
+	Color: particles.ColorCurve{
+		{T: 0, C: color.NRGBA{R: 255, G: 255, B: 200}},
+		{T: 0.3, C: color.NRGBA{R: 255, G: 160, B: 40}},
+		{T: 1, C: color.NRGBA{R: 120, G: 20, B: 0}},
+	},
+	Additive: true,
+}
+
+// Debris falling after the explosion
+var debrisConfig = particles.Config{
+	Burst:    24,
+	LifeMin:  0.8,
+	LifeMax:  1.4,
+	SpeedMin: 80,
+	SpeedMax: 300,
+	Angle:    -math.Pi / 2,
+	Spread:   math.Pi * 0.6,
+	Gravity:  500,
+	Size:     particles.Line(5, 3),
+	Alpha:    particles.Curve{{T: 0, V: 1}, {T: 0.7, V: 1}, {T: 1, V: 0}},
+	Color:    particles.ColorCurve{{T: 0, C: color.NRGBA{R: 150, G: 150, B: 160}}},
+}
+
+// initEffects creates particle system and bursts it on explosions,
+// explosions and player hits also shake the camera, it must be
+// called after initSound as it chains the event handler
+func initEffects(r *sdl.Renderer) {
+	effects = particles.NewSystem(maxParticles)
+	effectsRenderer, err = particles.NewRenderer(r)
+	perror(err)
+
+	next := gobject.OnEvent
+	gobject.OnEvent = func(e gobject.Event, gob *gobject.Gobject) {
+		if next != nil {
+			next(e, gob)
+		}
+		isPlayer := gob.Id == "player"
+		switch e {
+		case gobject.EventHit:
+			gob.Flash()
+			if isPlayer {
+				cam.AddTrauma(playerHitTrauma)
+			}
+		case gobject.EventExplosion:
+			x, y := float64(gob.X+gob.Width/2), float64(gob.Y+gob.Height/2)
+			effects.Burst(&explosionConfig, x, y)
+			effects.Burst(&debrisConfig, x, y)
+			if isPlayer {
+				cam.AddTrauma(playerExplosionTrauma)
+			} else {
+				cam.AddTrauma(explosionTrauma)
+			}
+		}
+	}
+}
+
+// freeEffects releases particle resources
+func freeEffects() {
+	effectsRenderer.Free()
+}
+
+// exhaust returns emitter attached to the bottom of the player ship,
+// player is a func as the ship is replaced on respawn
+func exhaust(player func() *gobject.Gobject) *particles.Emitter {
+	return &particles.Emitter{
+		Config: &exhaustConfig,
+		Follow: func() (float64, float64, bool) {
+			p := player()
+			return float64(p.X + p.Width/2), float64(p.Y + p.Height - 10), p.IsMoving
+		},
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"sdl_learn/text"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func loadBitmapFont(r *sdl.Renderer) (text.Font, func(), error) {
+	f, err := text.NewBitmap(r, BitmapFontFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Free, nil
+}
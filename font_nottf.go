@@ -0,0 +1,15 @@
+//go:build nottf
+
+package main
+
+import (
+	"sdl_learn/text"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// loadFont opens bitmap font, builds with nottf tag don't link SDL_ttf,
+// the bitmap font has a single size
+func loadFont(r *sdl.Renderer, size int) (text.Font, func(), error) {
+	return loadBitmapFont(r)
+}
@@ -0,0 +1,29 @@
+//go:build !nottf
+
+package main
+
+import (
+	"sdl_learn/logger"
+	"sdl_learn/text"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// loadFont opens TTF font of the size and falls back to the bitmap one
+func loadFont(r *sdl.Renderer, size int) (text.Font, func(), error) {
+	if err := ttf.Init(); err != nil {
+		logger.Error("unable to init ttf: %s", err.Error())
+		return loadBitmapFont(r)
+	}
+	f, err := text.NewTTF(FontFile, size)
+	if err != nil {
+		logger.Error("unable to open font %s: %s", FontFile, err.Error())
+		ttf.Quit()
+		return loadBitmapFont(r)
+	}
+	return f, func() {
+		f.Free()
+		ttf.Quit()
+	}, nil
+}
@@ -0,0 +1,22 @@
+package gobject
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sdl_learn/settings"
+)
+
+// enemyShoots rolls whether enemy fires on this move,
+// chance is one in eight, four or two depending on difficulty
+func enemyShoots() bool {
+	sides := map[settings.Difficulty]int64{
+		settings.Easy:   8,
+		settings.Normal: 4,
+		settings.Hard:   2,
+	}[settings.Current.Gameplay.Difficulty]
+	if sides == 0 {
+		sides = 4
+	}
+	val, err := rand.Int(rand.Reader, big.NewInt(sides))
+	return err == nil && val.Int64() == 0
+}
@@ -0,0 +1,25 @@
+package gobject
+
+// Event is something that happened to a game object
+type Event int
+
+const (
+	// Object fired
+	EventShoot Event = iota
+	// Object was hit
+	EventHit
+	// Object was destroyed
+	EventExplosion
+	// Object collected a bonus
+	EventPickup
+)
+
+// OnEvent is called on game object events, it may be called
+// from movement goroutines so it must be safe for that
+var OnEvent func(e Event, gob *Gobject)
+
+func emit(e Event, gob *Gobject) {
+	if OnEvent != nil {
+		OnEvent(e, gob)
+	}
+}
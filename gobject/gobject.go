@@ -3,15 +3,30 @@ package gobject
 import (
 	"context"
 	"crypto/rand"
+	"math"
 	"math/big"
+	"sdl_learn/inputs"
+	"sdl_learn/render"
+	"sdl_learn/settings"
+	"sync/atomic"
 
-	"github.com/veandco/go-sdl2/img"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+// EnemyMargin keeps enemies off the playfield side edges
+const EnemyMargin int32 = 100
+
+// Sprite effects
+const (
+	// Hit flash length in milliseconds
+	FlashTime uint32 = 150
+	// Tilt of the player moving sideways in degrees
+	BankAngle = 15.0
+)
+
 // GameObject interface
 type GameObject interface {
-	Draw(r *sdl.Renderer)
+	Draw(r render.Renderer)
 	Update()
 }
 
@@ -28,15 +43,34 @@ type Gobject struct {
 	// Sprite size, not full image size
 	MaxX, MaxY, Width, Height int32
 	// Holds image
-	Texture *sdl.Texture
+	Texture render.Texture
 	// Holds image
-	TextureDestruction *sdl.Texture
+	TextureDestruction render.Texture
 	// Holds image
-	TextureBullet *sdl.Texture
+	TextureBullet render.Texture
 	// Part of the spritesheet
 	Src sdl.Rect
 	// Part of the screen where to draw
 	Dest sdl.Rect
+	// Draw order
+	Layer render.Layer
+	Z     int32
+	// Spawn order, draws objects of the same Z oldest first
+	Seq uint64
+	// Rotation in degrees clockwise around Pivot, nil pivot is the middle
+	Angle float64
+	Pivot *sdl.Point
+	// Sprite scale around its middle, 0 is 1, collisions use unscaled Rect
+	ScaleX, ScaleY float64
+	Flip           render.Flip
+	// Tint and alpha, zero value keeps the image as is
+	Color sdl.Color
+	// Hits taken before destruction, 0 is one hit
+	HP int32
+	// Hits taken so far and ticks when the hit flash ends,
+	// both are set from movement goroutines
+	hits     atomic.Int32
+	flashEnd atomic.Uint32
 	// Is object moving
 	IsMoving          bool
 	IsShoot           bool
@@ -45,8 +79,11 @@ type Gobject struct {
 	Destroyed         map[string]int
 }
 
+// spawned counts created objects for Seq
+var spawned atomic.Uint64
+
 // NewGobject creates new game object
-func NewGobject(r *sdl.Renderer, file, filenameDestruction, filenameBullet, id string, x, y, maxX, maxY int32, isMoving bool) *Gobject {
+func NewGobject(l render.Loader, file, filenameDestruction, filenameBullet, id string, x, y, maxX, maxY int32, isMoving bool) *Gobject {
 	gob := &Gobject{
 		Filename:            file,
 		FilenameDestruction: filenameDestruction,
@@ -58,79 +95,78 @@ func NewGobject(r *sdl.Renderer, file, filenameDestruction, filenameBullet, id s
 		MaxY:                maxY,
 		Speed:               1,
 		IsMoving:            isMoving,
+		Seq:                 spawned.Add(1),
 	}
-	gob.Load(r)
+	gob.Load(l)
 	return gob
 }
 
 // Load texture
-func (gob *Gobject) Load(r *sdl.Renderer) {
-	image, err := img.Load(gob.Filename)
-	if err != nil {
-		panic(err)
-	}
-	defer image.Free()
-
-	gob.Texture, err = r.CreateTextureFromSurface(image)
+func (gob *Gobject) Load(l render.Loader) {
+	var err error
+	gob.Texture, err = l.LoadTexture(gob.Filename)
 	if err != nil {
 		panic(err)
 	}
 
 	if gob.FilenameDestruction != "" {
-		imageDestruction, err := img.Load(gob.FilenameDestruction)
-		if err != nil {
-			panic(err)
-		}
-		defer imageDestruction.Free()
-		gob.TextureDestruction, err = r.CreateTextureFromSurface(imageDestruction)
+		gob.TextureDestruction, err = l.LoadTexture(gob.FilenameDestruction)
 		if err != nil {
 			panic(err)
 		}
 	}
 
 	if gob.FilenameBullet != "" {
-		imageBullet, err := img.Load(gob.FilenameBullet)
-		if err != nil {
-			panic(err)
-		}
-		defer imageBullet.Free()
-		gob.TextureBullet, err = r.CreateTextureFromSurface(imageBullet)
+		gob.TextureBullet, err = l.LoadTexture(gob.FilenameBullet)
 		if err != nil {
 			panic(err)
 		}
 	}
 
 	// Query image size and calculate frame width and height
-	_, _, imageWidth, imageHeight, _ := gob.Texture.Query()
-	gob.Width = imageWidth
-	gob.Height = imageHeight
+	gob.Width, gob.Height = gob.Texture.Size()
 }
 
-// Free resources
+// Free resources, every texture drops its reference so shared
+// textures are freed with their last object
 func (gob *Gobject) Free() {
-	gob.Texture.Destroy()
+	if gob.Texture != nil {
+		gob.Texture.Free()
+		gob.Texture = nil
+	}
+	if gob.TextureDestruction != nil {
+		gob.TextureDestruction.Free()
+		gob.TextureDestruction = nil
+	}
+	if gob.TextureBullet != nil {
+		gob.TextureBullet.Free()
+		gob.TextureBullet = nil
+	}
 }
 
 // Update updates object state
-func (gob *Gobject) Update(r *sdl.Renderer) {
+func (gob *Gobject) Update(r render.Renderer) {
 	if gob.IsMoving {
-		keyStates := sdl.GetKeyboardState()
-		gob.Speed = 20
-		if keyStates[sdl.SCANCODE_LEFT] == 1 {
+		gob.Speed = settings.Current.Gameplay.PlayerSpeed
+		gob.Angle = 0
+		if inputs.Down(inputs.MoveLeft) {
 			if (gob.X - gob.Speed) > 0 {
 				gob.X -= gob.Speed
 			}
+			gob.Angle = -BankAngle
 			sdl.Delay(50)
-		} else if keyStates[sdl.SCANCODE_RIGHT] == 1 {
-			if (gob.X + gob.Speed + 70) < gob.MaxX {
+		} else if inputs.Down(inputs.MoveRight) {
+			if (gob.X + gob.Speed + gob.Width) < gob.MaxX {
 				gob.X += gob.Speed
 			}
+			gob.Angle = BankAngle
 			sdl.Delay(50)
 		}
-		if keyStates[sdl.SCANCODE_SPACE] == 1 {
+		if inputs.Down(inputs.Shoot) {
 			if gob.ShootDelay == 0 {
 				gob.IsShoot = true
-				gob.ShootDelay = 3
+				emit(EventShoot, gob)
+				gob.ShootDelay = settings.Current.Gameplay.ShootDelay
 			} else {
 				gob.ShootDelay -= 1
 			}
@@ -139,38 +175,103 @@ func (gob *Gobject) Update(r *sdl.Renderer) {
 }
 
 func (gob *Gobject) Rect() sdl.Rect {
-	x, y := int32(gob.X), int32(gob.Y)
-	_, _, imageWidth, imageHeight, _ := gob.Texture.Query()
 	return sdl.Rect{
-		X: x,
-		Y: y,
-		W: imageWidth,
-		H: imageHeight,
+		X: gob.X,
+		Y: gob.Y,
+		W: gob.Width,
+		H: gob.Height,
 	}
 }
 
+// SpriteRect returns part of the screen the sprite covers, Rect scaled around its middle
+func (gob *Gobject) SpriteRect() sdl.Rect {
+	rect := gob.Rect()
+	sx, sy := gob.ScaleX, gob.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	w, h := int32(float64(rect.W)*sx), int32(float64(rect.H)*sy)
+	return sdl.Rect{X: rect.X + (rect.W-w)/2, Y: rect.Y + (rect.H-h)/2, W: w, H: h}
+}
+
+// Flash turns sprite white for FlashTime
+func (gob *Gobject) Flash() {
+	gob.flashEnd.Store(sdl.GetTicks() + FlashTime)
+}
+
+// Hit counts a hit and reports whether it destroys the object
+func (gob *Gobject) Hit() bool {
+	return gob.hits.Add(1) >= max(gob.HP, 1)
+}
+
+// Face turns sprite so its bottom points at x, y
+func (gob *Gobject) Face(x, y int32) {
+	dx := float64(x - (gob.X + gob.Width/2))
+	dy := float64(y - (gob.Y + gob.Height/2))
+	if dx == 0 && dy == 0 {
+		return
+	}
+	gob.Angle = math.Atan2(dy, dx)*180/math.Pi - 90
+}
+
+// Options returns sprite transforms of the object
+func (gob *Gobject) Options() render.Options {
+	o := render.Options{
+		Angle: gob.Angle,
+		Pivot: gob.Pivot,
+		Flip:  gob.Flip,
+		Color: gob.Color,
+	}
+	if end, now := gob.flashEnd.Load(), sdl.GetTicks(); now < end {
+		o.Flash = float32(end-now) / float32(FlashTime)
+	}
+	return o
+}
+
 // Draw object
-func (gob *Gobject) Draw(r *sdl.Renderer) {
-	dst := gob.Rect()
+func (gob *Gobject) Draw(r render.Renderer) {
+	dst := gob.SpriteRect()
+	o := gob.Options()
 	if gob.IsMoving {
-		r.Copy(gob.Texture, nil, &dst)
+		r.DrawSpriteEx(gob.Texture, nil, &dst, &o)
 	} else {
-		r.Copy(gob.TextureDestruction, nil, &dst)
+		r.DrawSpriteEx(gob.TextureDestruction, nil, &dst, &o)
+	}
+}
+
+// Submit adds object sprite to the render queue
+func (gob *Gobject) Submit(q *render.Queue) {
+	texture := gob.Texture
+	if !gob.IsMoving {
+		texture = gob.TextureDestruction
 	}
+	q.Submit(render.Sprite{
+		Texture: texture,
+		Dst:     gob.SpriteRect(),
+		Layer:   gob.Layer,
+		Z:       gob.Z,
+		Seq:     gob.Seq,
+		Options: gob.Options(),
+	})
 }
 
-func (gob *Gobject) ShootDown(r *sdl.Renderer, player *Gobject) {
+func (gob *Gobject) ShootDown(r render.Renderer, player *Gobject) {
 	if gob.IsMoving {
-		r.SetDrawColor(0, 255, 0, 0)
+		r.SetColor(sdl.Color{R: 0, G: 255, B: 0, A: 0})
 		r.DrawLine(gob.X+60, gob.Y+100, gob.X+60, gob.Y+300)
+		emit(EventShoot, gob)
 		if gob.X+60 >= player.X && gob.X+60 <= player.X+50 && player.Y <= gob.Y+300 {
+			emit(EventHit, player)
 			player.IsMoving = false
 			player.Destroy(r)
 		}
 	}
 }
 
-func (gob *Gobject) RandomMoving(r *sdl.Renderer, player *Gobject) {
+func (gob *Gobject) RandomMoving(r render.Renderer, player *Gobject) {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func(ctx context.Context) {
 		var min int32 = 20
@@ -183,19 +284,18 @@ func (gob *Gobject) RandomMoving(r *sdl.Renderer, player *Gobject) {
 				for i := 0; i < 2; i++ {
 					if gob.IsMoving {
 						sdl.Delay(1500)
-						maxRand := big.NewInt(4)
-						if val, err := rand.Int(rand.Reader, maxRand); err == nil && val.Int64() > 2 && gob.IsMoving {
+						if enemyShoots() && gob.IsMoving {
 							gob.ShootDown(r, player)
 							sdl.Delay(1500)
 							if (gob.X-gob.Speed) > 0 && gob.IsMoving {
 								gob.X -= gob.Speed
 								sdl.Delay(1500)
 							}
-							if (gob.Y+gob.Speed+100) < gob.MaxY && gob.IsMoving {
+							if (gob.Y+gob.Speed+gob.Height) < gob.MaxY && gob.IsMoving {
 								gob.Y += gob.Speed
 								sdl.Delay(1500)
 							}
-							if (gob.X+gob.Speed+200) < gob.MaxX && gob.IsMoving {
+							if (gob.X+gob.Speed+gob.Width) < gob.MaxX && gob.IsMoving {
 								gob.X += gob.Speed
 								sdl.Delay(1500)
 							}
@@ -205,7 +305,7 @@ func (gob *Gobject) RandomMoving(r *sdl.Renderer, player *Gobject) {
 							}
 						} else {
 							sdl.Delay(1500)
-							if (gob.X+gob.Speed+200) < gob.MaxX && gob.IsMoving {
+							if (gob.X+gob.Speed+gob.Width) < gob.MaxX && gob.IsMoving {
 								gob.X += gob.Speed
 								sdl.Delay(1500)
 							}
@@ -217,7 +317,7 @@ func (gob *Gobject) RandomMoving(r *sdl.Renderer, player *Gobject) {
 								gob.X -= gob.Speed
 								sdl.Delay(1500)
 							}
-							if (gob.Y+gob.Speed+100) < gob.MaxY && gob.IsMoving {
+							if (gob.Y+gob.Speed+gob.Height) < gob.MaxY && gob.IsMoving {
 								gob.Y += gob.Speed
 								sdl.Delay(1500)
 							}
@@ -240,19 +340,20 @@ func (gob *Gobject) RandomMoving(r *sdl.Renderer, player *Gobject) {
 	}()
 }
 
-func (gob *Gobject) Destroy(r *sdl.Renderer) {
+func (gob *Gobject) Destroy(r render.Renderer) {
 	if !gob.IsMoving {
+		emit(EventExplosion, gob)
 		dst := gob.Rect()
-		r.Copy(gob.TextureDestruction, nil, &dst)
+		r.DrawSprite(gob.TextureDestruction, nil, &dst)
 		sdl.Delay(500)
 		gob.Free()
 	}
 }
 
-func (gob *Gobject) LeftMoving(r *sdl.Renderer, player *Gobject) {
+func (gob *Gobject) LeftMoving(r render.Renderer, player *Gobject) {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func(ctx context.Context) {
-		gob.Speed = 2
+		gob.Speed = settings.Current.Gameplay.EnemySpeed
 		if gob.IsMoving {
 			select {
 			case <-ctx.Done():
@@ -260,13 +361,11 @@ func (gob *Gobject) LeftMoving(r *sdl.Renderer, player *Gobject) {
 			default:
 				if gob.IsMoving {
 					sdl.Delay(1500)
-					maxRand := big.NewInt(4)
-					val, err := rand.Int(rand.Reader, maxRand)
-					if err == nil && val.Int64() > 2 {
+					if enemyShoots() {
 						gob.ShootDown(r, player)
 					}
 					sdl.Delay(1500)
-					if (gob.X-gob.Speed) > 100 && gob.IsMoving {
+					if (gob.X-gob.Speed) > EnemyMargin && gob.IsMoving {
 						gob.X -= gob.Speed
 						sdl.Delay(1500)
 					}
@@ -282,10 +381,10 @@ func (gob *Gobject) LeftMoving(r *sdl.Renderer, player *Gobject) {
 	}()
 }
 
-func (gob *Gobject) RightMoving(r *sdl.Renderer, player *Gobject) {
+func (gob *Gobject) RightMoving(r render.Renderer, player *Gobject) {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func(ctx context.Context) {
-		gob.Speed = 2
+		gob.Speed = settings.Current.Gameplay.EnemySpeed
 		if gob.IsMoving {
 			select {
 			case <-ctx.Done():
@@ -293,13 +392,11 @@ func (gob *Gobject) RightMoving(r *sdl.Renderer, player *Gobject) {
 			default:
 				if gob.IsMoving {
 					sdl.Delay(1500)
-					maxRand := big.NewInt(4)
-					val, err := rand.Int(rand.Reader, maxRand)
-					if err == nil && val.Int64() > 2 {
+					if enemyShoots() {
 						gob.ShootDown(r, player)
 					}
 					sdl.Delay(1500)
-					if (gob.X+gob.Speed) < 1100 && gob.IsMoving {
+					if (gob.X+gob.Speed) < gob.MaxX-gob.Width-EnemyMargin && gob.IsMoving {
 						gob.X += gob.Speed
 						sdl.Delay(1500)
 					}
@@ -317,7 +414,7 @@ func (gob *Gobject) RightMoving(r *sdl.Renderer, player *Gobject) {
 
 func (gob *Gobject) GetBulletRect(startX, startY int32) sdl.Rect {
 	x, y := startX+32, startY-35
-	_, _, imageWidth, imageHeight, _ := gob.TextureBullet.Query()
+	imageWidth, imageHeight := gob.TextureBullet.Size()
 	return sdl.Rect{
 		X: x,
 		Y: y,
@@ -326,10 +423,10 @@ func (gob *Gobject) GetBulletRect(startX, startY int32) sdl.Rect {
 	}
 }
 
-func (gob *Gobject) UpMoving(r *sdl.Renderer, objects map[string]*Gobject, bullets map[string]*Gobject, player *Gobject) {
+func (gob *Gobject) UpMoving(r render.Renderer, objects map[string]*Gobject, bullets map[string]*Gobject, player *Gobject) {
 	ctx, cancel := context.WithCancel(context.Background())
 	go func(ctx context.Context) {
-		gob.Speed = 1
+		gob.Speed = settings.Current.Gameplay.BulletSpeed
 		if gob.IsMoving {
 			select {
 			case <-ctx.Done():
@@ -337,14 +434,19 @@ func (gob *Gobject) UpMoving(r *sdl.Renderer, objects map[string]*Gobject, bulle
 			default:
 				if gob.Y > 0 && gob.IsMoving {
 					sdl.Delay(100)
-					gob.Y -= gob.Speed * 70
+					gob.Y -= gob.Speed
 					for key, obj := range objects {
 						if gob.IsMoving && !(gob.X >= obj.X+obj.Rect().W ||
 							gob.X+gob.Rect().W <= obj.X ||
 							gob.Y >= obj.Y+obj.Rect().H ||
 							gob.Y+gob.Rect().H <= obj.Y) {
-							obj.IsMoving = false
+							emit(EventHit, obj)
 							gob.IsMoving = false
+							// Objects with HP left keep flying and flash
+							if !obj.Hit() {
+								continue
+							}
+							obj.IsMoving = false
 							player.Destroyed[obj.Id] = 100
 							obj.Destroy(r)
 							//player.Score += 100
@@ -0,0 +1,134 @@
+package gobject
+
+import (
+	"sdl_learn/render"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestDraw(t *testing.T) {
+	rec := render.NewRecorder(16, 8)
+	gob := NewGobject(rec, "ship.png", "boom.png", "", "ship", 10, 20, 800, 600, true)
+	gob.ScaleX, gob.ScaleY = 2, 2
+	gob.Draw(rec)
+	gob.IsMoving = false
+	gob.Draw(rec)
+
+	commands := rec.Commands()
+	if len(commands) != 2 {
+		t.Fatalf("got %d commands want 2", len(commands))
+	}
+	want := sdl.Rect{X: 2, Y: 16, W: 32, H: 16}
+	for i, file := range []string{"ship.png", "boom.png"} {
+		c := commands[i]
+		if c.Op != render.OpSpriteEx || c.Texture.(*render.NullTexture).File != file {
+			t.Errorf("command %d is %v of %v, want sprite of %s", i, c.Op, c.Texture, file)
+		}
+		if *c.Dst != want {
+			t.Errorf("command %d dst %v want %v", i, *c.Dst, want)
+		}
+	}
+}
+
+func TestSubmitSpawnOrder(t *testing.T) {
+	rec := render.NewRecorder(16, 16)
+	first := NewGobject(rec, "ufo.png", "", "", "first", 0, 0, 800, 600, true)
+	second := NewGobject(rec, "ufo.png", "", "", "second", 50, 0, 800, 600, true)
+
+	// Submit order differs, like iterating the enemies map
+	q := render.NewQueue()
+	second.Submit(q)
+	first.Submit(q)
+	q.Flush(rec, nil)
+
+	commands := rec.Commands()
+	if len(commands) != 2 || rec.Count(render.OpSprite) != 2 {
+		t.Fatalf("got %v want two plain sprites", commands)
+	}
+	if commands[0].Dst.X != first.X || commands[1].Dst.X != second.X {
+		t.Errorf("drawn at %d then %d, want older object first", commands[0].Dst.X, commands[1].Dst.X)
+	}
+}
+
+func TestShootDownMisses(t *testing.T) {
+	rec := render.NewRecorder(16, 16)
+	enemy := NewGobject(rec, "ufo.png", "", "", "ufo", 0, 0, 800, 600, true)
+	player := NewGobject(rec, "ship.png", "", "", "player", 400, 500, 800, 600, true)
+	enemy.ShootDown(rec, player)
+
+	if !player.IsMoving {
+		t.Error("player far from the beam was hit")
+	}
+	commands := rec.Commands()
+	if len(commands) != 2 || commands[0].Op != render.OpSetColor || commands[1].Op != render.OpLine {
+		t.Fatalf("got %v want color and beam line", commands)
+	}
+	if c := commands[1]; c.X1 != 60 || c.Y1 != 100 || c.X2 != 60 || c.Y2 != 300 {
+		t.Errorf("beam %d,%d %d,%d", c.X1, c.Y1, c.X2, c.Y2)
+	}
+}
+
+func TestHit(t *testing.T) {
+	rec := render.NewRecorder(16, 16)
+	ufo := NewGobject(rec, "ufo.png", "", "", "ufo", 0, 0, 800, 600, true)
+	if !ufo.Hit() {
+		t.Error("object without HP survived a hit")
+	}
+
+	boss := NewGobject(rec, "ufo.png", "", "", "boss", 0, 0, 800, 600, true)
+	boss.HP = 50
+	// Bullets hit from their own goroutines
+	var wg sync.WaitGroup
+	var destroyed atomic.Int32
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if boss.Hit() {
+				destroyed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := destroyed.Load(); n != 51 {
+		t.Errorf("%d of 100 hits destroyed boss with 50 HP, want 51", n)
+	}
+}
+
+func TestFlash(t *testing.T) {
+	rec := render.NewRecorder(16, 16)
+	gob := NewGobject(rec, "ufo.png", "", "", "ufo", 0, 0, 800, 600, true)
+	if o := gob.Options(); o.Flash != 0 {
+		t.Fatalf("flash %v before a hit", o.Flash)
+	}
+	gob.Flash()
+	gob.Draw(rec)
+	if c := rec.Commands()[0]; c.Options.Flash <= 0 || c.Options.Flash > 1 {
+		t.Errorf("drawn flash %v", c.Options.Flash)
+	}
+}
+
+func TestFreeSharedTextures(t *testing.T) {
+	rec := render.NewRecorder(16, 16)
+	a := NewGobject(rec, "ufo.png", "exp.png", "bullet.png", "a", 0, 0, 800, 600, true)
+	b := NewGobject(rec, "ufo.png", "exp.png", "bullet.png", "b", 50, 0, 800, 600, true)
+	if a.TextureBullet != b.TextureBullet || rec.Textures() != 3 {
+		t.Fatalf("two objects loaded %d textures, want 3 shared", rec.Textures())
+	}
+	a.Free()
+	if n := rec.Textures(); n != 3 {
+		t.Errorf("first free left %d textures, want 3", n)
+	}
+	// Second Free of the same object must not drop references of b
+	a.Free()
+	if n := rec.Textures(); n != 3 {
+		t.Errorf("repeated free left %d textures, want 3", n)
+	}
+	b.Free()
+	if n := rec.Textures(); n != 0 {
+		t.Errorf("%d textures left after freeing all objects", n)
+	}
+}
@@ -1,17 +1,17 @@
 package gobject
 
 import (
-	"github.com/veandco/go-sdl2/sdl"
+	"sdl_learn/render"
 )
 
 type Manager struct {
-	R         *sdl.Renderer
+	R         render.Renderer
 	PlayerObj *Gobject
 	Enemies   map[string]*Gobject
 	Bullets   map[string]*Gobject
 }
 
-func NewManager(player *Gobject, r *sdl.Renderer, enemies map[string]*Gobject, bullets map[string]*Gobject) *Manager {
+func NewManager(player *Gobject, r render.Renderer, enemies map[string]*Gobject, bullets map[string]*Gobject) *Manager {
 	return &Manager{
 		R:         r,
 		PlayerObj: player,
@@ -0,0 +1,136 @@
+// Package golden compares rendered frames with stored images.
+// Frames are rendered headless: SDL runs with the dummy video driver
+// and the software renderer draws into a surface, so results do not
+// depend on the GPU. On mismatch the frame and a diff image are
+// written next to the golden one.
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sdl_learn/raster"
+	"sdl_learn/screenshot"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Options of the comparison
+type Options struct {
+	// Directory of the golden images
+	Dir string
+	// Largest channel difference still treated as equal
+	Tolerance int
+	// Share of pixels allowed to differ, from 0 to 1
+	MaxBad float64
+	// Write frames as new golden images instead of comparing
+	Update bool
+}
+
+// DefaultOptions allow small differences of blending rounding
+func DefaultOptions(dir string) Options {
+	return Options{Dir: dir, Tolerance: 8, MaxBad: 0.001}
+}
+
+// Headless starts SDL without a window and returns software renderer
+// drawing into a w x h surface, free stops SDL
+func Headless(w, h int32) (*sdl.Renderer, func(), error) {
+	os.Setenv("SDL_VIDEODRIVER", "dummy")
+	os.Setenv("SDL_AUDIODRIVER", "dummy")
+	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+		return nil, nil, err
+	}
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, w, h, 32, sdl.PIXELFORMAT_ABGR8888)
+	if err != nil {
+		sdl.Quit()
+		return nil, nil, err
+	}
+	r, err := sdl.CreateSoftwareRenderer(surface)
+	if err != nil {
+		surface.Free()
+		sdl.Quit()
+		return nil, nil, err
+	}
+	free := func() {
+		r.Destroy()
+		surface.Free()
+		sdl.Quit()
+	}
+	return r, free, nil
+}
+
+// Compare counts pixels of got differing from want by more than
+// tolerance and returns diff image: want faded with bad pixels red,
+// for images of different sizes the diff covers both and pixels
+// outside one of them are bad
+func Compare(got, want image.Image, tolerance int) (int, *image.NRGBA) {
+	g, w := raster.FromImage(got), raster.FromImage(want)
+	diff := raster.New(max(g.Width, w.Width), max(g.Height, w.Height))
+	bad := 0
+	for y := 0; y < diff.Height; y++ {
+		for x := 0; x < diff.Width; x++ {
+			i := (y*diff.Width + x) * 4
+			if x >= g.Width || y >= g.Height || x >= w.Width || y >= w.Height {
+				bad++
+				copy(diff.Pix[i:i+4], []byte{255, 0, 0, 255})
+				continue
+			}
+			gp, wp := g.Pix[(y*g.Width+x)*4:], w.Pix[(y*w.Width+x)*4:]
+			delta := 0
+			for k := 0; k < 4; k++ {
+				d := int(gp[k]) - int(wp[k])
+				delta = max(delta, d, -d)
+			}
+			if delta > tolerance {
+				bad++
+				copy(diff.Pix[i:i+4], []byte{255, 0, 0, 255})
+				continue
+			}
+			grey := byte((int(wp[0]) + int(wp[1]) + int(wp[2])) / 12)
+			copy(diff.Pix[i:i+4], []byte{grey, grey, grey, 255})
+		}
+	}
+	return bad, diff.Image()
+}
+
+// Check compares frame with the golden image name.png, with Update
+// it stores the frame instead
+func Check(name string, got image.Image, o Options) error {
+	path := filepath.Join(o.Dir, name+".png")
+	gotPath := filepath.Join(o.Dir, name+".got.png")
+	diffPath := filepath.Join(o.Dir, name+".diff.png")
+	if o.Update {
+		os.Remove(gotPath)
+		os.Remove(diffPath)
+		return screenshot.Save(got, path)
+	}
+
+	want, err := raster.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: no golden image, run with update to create it", name)
+	}
+	if err != nil {
+		return err
+	}
+	bad, diff := Compare(got, want, o.Tolerance)
+	size := diff.Rect.Dx() * diff.Rect.Dy()
+	// Different size always fails however few pixels it adds
+	sameSize := got.Bounds().Size() == want.Bounds().Size()
+	if sameSize && float64(bad) <= o.MaxBad*float64(size) {
+		os.Remove(gotPath)
+		os.Remove(diffPath)
+		return nil
+	}
+	if err := screenshot.Save(got, gotPath); err != nil {
+		return err
+	}
+	if err := screenshot.Save(diff, diffPath); err != nil {
+		return err
+	}
+	if !sameSize {
+		return fmt.Errorf("%s: size %v want %v, see %s", name, got.Bounds().Size(), want.Bounds().Size(), diffPath)
+	}
+	return fmt.Errorf("%s: %d of %d pixels differ, see %s", name, bad, size, diffPath)
+}
@@ -0,0 +1,161 @@
+package golden
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sdl_learn/raster"
+	"sdl_learn/screenshot"
+	"strings"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+var update = flag.Bool("update", false, "write golden images instead of comparing")
+
+func filled(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	grey := color.NRGBA{100, 100, 100, 255}
+	tests := []struct {
+		name      string
+		got       *image.NRGBA
+		tolerance int
+		bad       int
+	}{
+		{"equal", filled(4, 2, grey), 0, 0},
+		{"within tolerance", filled(4, 2, color.NRGBA{108, 92, 100, 255}), 8, 0},
+		{"over tolerance", filled(4, 2, color.NRGBA{109, 100, 100, 255}), 8, 8},
+		{"alpha counts", filled(4, 2, color.NRGBA{100, 100, 100, 200}), 8, 8},
+		// Diff covers both images, pixels outside the overlap are bad
+		{"size mismatch", filled(2, 4, grey), 8, 12},
+		{"smaller", filled(1, 1, grey), 255, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad, diff := Compare(tt.got, filled(4, 2, grey), tt.tolerance)
+			if bad != tt.bad {
+				t.Errorf("got %d bad pixels want %d", bad, tt.bad)
+			}
+			if diff == nil {
+				t.Fatal("no diff image")
+			}
+			size := tt.got.Rect.Size()
+			if want := image.Rect(0, 0, max(size.X, 4), max(size.Y, 2)); diff.Rect != want {
+				t.Errorf("diff bounds %v want %v", diff.Rect, want)
+			}
+		})
+	}
+
+	// Differing pixels are red, others are the golden image faded
+	got := filled(2, 1, grey)
+	got.SetNRGBA(1, 0, color.NRGBA{A: 255})
+	_, diff := Compare(got, filled(2, 1, grey), 8)
+	if c := diff.NRGBAAt(0, 0); c != (color.NRGBA{25, 25, 25, 255}) {
+		t.Errorf("equal pixel %v", c)
+	}
+	if c := diff.NRGBAAt(1, 0); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("bad pixel %v", c)
+	}
+	_, diff = Compare(filled(1, 2, grey), filled(2, 1, grey), 8)
+	if a, b, c := diff.NRGBAAt(0, 0), diff.NRGBAAt(1, 0), diff.NRGBAAt(0, 1); a.R != 25 || b.G != 0 || c.G != 0 {
+		t.Errorf("size mismatch diff %v %v %v", a, b, c)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	want := filled(10, 10, color.NRGBA{A: 255})
+	o := Options{Dir: dir, Tolerance: 8, MaxBad: 0.02}
+	if err := Check("frame", want, o); err == nil || !strings.Contains(err.Error(), "no golden") {
+		t.Fatalf("missing golden image gave %v", err)
+	}
+	o.Update = true
+	if err := Check("frame", want, o); err != nil {
+		t.Fatal(err)
+	}
+	o.Update = false
+
+	// Two bad pixels of a hundred are allowed, three are not
+	got := filled(10, 10, color.NRGBA{A: 255})
+	got.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	got.SetNRGBA(1, 0, color.NRGBA{R: 255, A: 255})
+	if err := Check("frame", got, o); err != nil {
+		t.Errorf("within MaxBad: %v", err)
+	}
+	got.SetNRGBA(2, 0, color.NRGBA{R: 255, A: 255})
+	if err := Check("frame", got, o); err == nil {
+		t.Error("over MaxBad passed")
+	}
+	for _, name := range []string{"frame.got.png", "frame.diff.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("failed check did not write %s: %v", name, err)
+		}
+	}
+
+	// Passing check removes images of the earlier failure
+	if err := Check("frame", want, o); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "frame.diff.png")); !os.IsNotExist(err) {
+		t.Errorf("diff image kept after pass: %v", err)
+	}
+	if err := Check("frame", filled(5, 5, color.NRGBA{A: 255}), o); err == nil {
+		t.Error("size mismatch passed")
+	}
+	// Extra row is few bad pixels but the size still has to match
+	o.MaxBad = 0.5
+	if err := Check("frame", filled(10, 11, color.NRGBA{A: 255}), o); err == nil || !strings.Contains(err.Error(), "size") {
+		t.Errorf("taller frame gave %v", err)
+	}
+	diff, err := raster.Load(filepath.Join(dir, "frame.diff.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Bounds() != image.Rect(0, 0, 10, 11) {
+		t.Errorf("size mismatch diff %v", diff.Bounds())
+	}
+}
+
+// TestHeadless draws shapes with the headless renderer and compares
+// them with testdata/shapes.png, run it with -update to rewrite it
+func TestHeadless(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	r, free, err := Headless(64, 48)
+	if err != nil {
+		t.Skipf("no headless renderer: %s", err)
+	}
+	defer free()
+
+	r.SetDrawColor(10, 20, 40, 255)
+	r.Clear()
+	r.SetDrawColor(200, 60, 30, 255)
+	r.FillRect(&sdl.Rect{X: 8, Y: 8, W: 20, H: 12})
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	r.SetDrawColor(40, 220, 90, 128)
+	r.FillRect(&sdl.Rect{X: 18, Y: 14, W: 30, H: 20})
+	r.SetDrawColor(255, 255, 255, 255)
+	r.DrawLine(0, 47, 63, 0)
+	r.DrawRect(&sdl.Rect{X: 2, Y: 2, W: 60, H: 44})
+
+	img, err := screenshot.Capture(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := DefaultOptions("testdata")
+	o.Update = *update
+	if err := Check("shapes", img, o); err != nil {
+		t.Error(err)
+	}
+}
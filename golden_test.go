@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sdl_learn/golden"
+	"sdl_learn/raster"
+	"sdl_learn/render"
+	"sdl_learn/scene"
+	"sdl_learn/screenshot"
+	"strings"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+var update = flag.Bool("update", false, "write golden images instead of comparing")
+
+// Scenes rendered by TestGolden, they must look the same on every run
+var goldenScenes = []scene.Name{scene.Title, scene.Options, scene.HighScores}
+
+// Frames simulated before a scene is captured and star field seed
+const (
+	goldenFrames = 30
+	goldenSeed   = 1
+)
+
+// TestGolden renders scenes headless with default settings and
+// compares them with images in testdata/golden, run it with -update
+// to write the images. Text is drawn with a generated bitmap font so
+// the images don't depend on the game assets
+func TestGolden(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	dir := t.TempDir()
+	// Options scene saves settings when it exits
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	FontFile = filepath.Join(dir, "missing.ttf")
+	BitmapFontFile = filepath.Join(dir, "font.fnt")
+	if err := writeTestFont(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var freeHeadless func()
+	var err error
+	rend, freeHeadless, err = golden.Headless(WindowWidth, WindowHeight)
+	if err != nil {
+		t.Skipf("no headless renderer: %s", err)
+	}
+	defer freeHeadless()
+	screen = render.NewSDL(rend)
+	setupView()
+
+	freeGame := initGame(goldenSeed)
+	defer freeGame()
+
+	options := golden.DefaultOptions(filepath.Join("testdata", "golden"))
+	options.Update = *update
+	for _, name := range goldenScenes {
+		scenes.Reset(name)
+		frameDelta = 1000 / 60
+		for i := 0; i < goldenFrames; i++ {
+			stars.Update(1)
+			scenes.Update()
+		}
+		clearView(sdl.Color{R: 0, G: 0, B: 0, A: 255})
+		stars.Draw(rend, &sdl.Rect{W: WindowWidth, H: WindowHeight})
+		scenes.Draw(rend)
+
+		img, err := screenshot.Capture(rend)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := golden.Check(string(name), img, options); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// Cells of the generated font, glyphs are a bit smaller so
+// neighbours don't touch
+const (
+	testGlyphW = 12
+	testGlyphH = 20
+)
+
+// writeTestFont writes BMFont with printable ASCII to dir, every glyph
+// is a distinct pattern of blocks made from its code
+func writeTestFont(dir string) error {
+	const first, last, columns = 33, 126, 16
+	rows := (last - first + columns) / columns
+	sheet := raster.New(columns*testGlyphW, rows*testGlyphH)
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	var fnt strings.Builder
+	fmt.Fprintf(&fnt, "info face=\"test\" size=%d\n", testGlyphH)
+	fmt.Fprintf(&fnt, "common lineHeight=%d base=%d pages=1\n", testGlyphH+4, testGlyphH)
+	fmt.Fprintf(&fnt, "page id=0 file=\"font.png\"\n")
+	fmt.Fprintf(&fnt, "char id=32 x=0 y=0 width=0 height=0 xoffset=0 yoffset=0 xadvance=%d page=0\n", testGlyphW)
+	for ch := first; ch <= last; ch++ {
+		x, y := (ch-first)%columns*testGlyphW, (ch-first)/columns*testGlyphH
+		// 3x5 blocks of 3 pixels, bits of the code pick the blocks
+		for bit := 0; bit < 15; bit++ {
+			if (ch*2654435761)>>(bit+7)&1 == 1 {
+				bx, by := x+1+bit%3*3, y+2+bit/3*3
+				sheet.FillRect(image.Rect(bx, by, bx+3, by+3), white)
+			}
+		}
+		fmt.Fprintf(&fnt, "char id=%d x=%d y=%d width=%d height=%d xoffset=0 yoffset=0 xadvance=%d page=0\n",
+			ch, x, y, testGlyphW, testGlyphH, testGlyphW)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "font.fnt"), []byte(fnt.String()), 0o644); err != nil {
+		return err
+	}
+	return screenshot.Save(sheet.Image(), filepath.Join(dir, "font.png"))
+}
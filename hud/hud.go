@@ -0,0 +1,126 @@
+package hud
+
+import (
+	"sdl_learn/text"
+	"strconv"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Anchor is a corner or edge of the screen the item is attached to
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// Margin from the screen edges in pixels
+const Margin int32 = 10
+
+// Item is a label attached to a screen anchor
+type Item struct {
+	Label  *text.Label
+	Anchor Anchor
+	// Offset from the anchor, positive values move towards the screen center
+	OffsetX, OffsetY int32
+	Hidden           bool
+}
+
+// Position returns top left corner of the item on the screen of given size
+func (it *Item) Position(screenW, screenH int32) (int32, int32) {
+	w, h := it.Label.Size()
+	var x, y int32
+	switch it.Anchor {
+	case TopLeft, BottomLeft:
+		x = Margin + it.OffsetX
+	case Top, Bottom:
+		x = (screenW-w)/2 + it.OffsetX
+	case TopRight, BottomRight:
+		x = screenW - w - Margin - it.OffsetX
+	}
+	switch it.Anchor {
+	case TopLeft, Top, TopRight:
+		y = Margin + it.OffsetY
+	default:
+		y = screenH - h - Margin - it.OffsetY
+	}
+	return x, y
+}
+
+// HUD shows game state over the playfield
+type HUD struct {
+	Score, HighScore, Lives, Wave, FPS *Item
+	ShowFPS                            bool
+	frames                             int
+	fpsStart                           uint32
+}
+
+// NewHUD creates HUD drawn with the font
+func NewHUD(f text.Font, color sdl.Color) *HUD {
+	line := f.LineHeight()
+	h := &HUD{
+		Score:     &Item{Label: text.NewLabel(f, color), Anchor: TopLeft},
+		HighScore: &Item{Label: text.NewLabel(f, color), Anchor: Top},
+		Lives:     &Item{Label: text.NewLabel(f, color), Anchor: TopRight},
+		Wave:      &Item{Label: text.NewLabel(f, color), Anchor: TopRight, OffsetY: line},
+		FPS:       &Item{Label: text.NewLabel(f, color), Anchor: BottomLeft},
+		fpsStart:  sdl.GetTicks(),
+	}
+	h.Set(0, 0, 0, 0)
+	return h
+}
+
+// Set updates displayed values, labels are rendered again only when changed
+func (h *HUD) Set(score, highScore, lives, wave int) {
+	if score > highScore {
+		highScore = score
+	}
+	h.Score.Label.SetText("SCORE " + strconv.Itoa(score))
+	h.HighScore.Label.SetText("HI " + strconv.Itoa(highScore))
+	h.Lives.Label.SetText("LIVES " + strconv.Itoa(lives))
+	h.Wave.Label.SetText("WAVE " + strconv.Itoa(wave))
+}
+
+// Frame counts rendered frame, FPS value is refreshed once per second
+func (h *HUD) Frame() {
+	h.frames++
+	now := sdl.GetTicks()
+	if elapsed := now - h.fpsStart; elapsed >= 1000 {
+		h.FPS.Label.SetText("FPS " + strconv.Itoa(int(uint32(h.frames)*1000/elapsed)))
+		h.frames = 0
+		h.fpsStart = now
+	}
+}
+
+// Items returns all HUD items
+func (h *HUD) Items() []*Item {
+	return []*Item{h.Score, h.HighScore, h.Lives, h.Wave, h.FPS}
+}
+
+// Draw renders visible items
+func (h *HUD) Draw(r *sdl.Renderer) {
+	w, ht := r.GetLogicalSize()
+	if w == 0 || ht == 0 {
+		w, ht, _ = r.GetOutputSize()
+	}
+	h.FPS.Hidden = !h.ShowFPS
+	for _, it := range h.Items() {
+		if it.Hidden {
+			continue
+		}
+		x, y := it.Position(w, ht)
+		it.Label.Draw(r, x, y)
+	}
+}
+
+// Free resources
+func (h *HUD) Free() {
+	for _, it := range h.Items() {
+		it.Label.Free()
+	}
+}
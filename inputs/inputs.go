@@ -4,19 +4,117 @@ import (
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-func Listen(isRunning bool) (bool, bool) {
-	var isExit bool
-	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-		switch event.GetType() {
-		case sdl.QUIT:
-			return false, true
-		case sdl.KEYDOWN:
-			if 1 == sdl.GetKeyboardState()[sdl.SCANCODE_PAUSE] {
-				return !isRunning, isExit
-			} else {
-				return isRunning, isExit
-			}
+// Action is a game command bound to a key
+type Action int
+
+const (
+	MoveLeft Action = iota
+	MoveRight
+	Shoot
+	Pause
+)
+
+// Actions lists all bindable actions in menu order
+var Actions = []Action{MoveLeft, MoveRight, Shoot, Pause}
+
+// Bindings maps actions to keys, can be changed while running
+var Bindings = map[Action]sdl.Scancode{
+	MoveLeft:  sdl.SCANCODE_LEFT,
+	MoveRight: sdl.SCANCODE_RIGHT,
+	Shoot:     sdl.SCANCODE_SPACE,
+	Pause:     sdl.SCANCODE_PAUSE,
+}
+
+// String returns human readable action name
+func (a Action) String() string {
+	switch a {
+	case MoveLeft:
+		return "Left"
+	case MoveRight:
+		return "Right"
+	case Shoot:
+		return "Shoot"
+	case Pause:
+		return "Pause"
+	}
+	return "Unknown"
+}
+
+// key returns name of the action in settings controls
+func (a Action) key() string {
+	switch a {
+	case MoveLeft:
+		return "left"
+	case MoveRight:
+		return "right"
+	case Shoot:
+		return "shoot"
+	case Pause:
+		return "pause"
+	}
+	return ""
+}
+
+// Bind sets key of the action, an action already using the key
+// gets the old key of this one so no key does two things
+func Bind(a Action, code sdl.Scancode) {
+	old := Bindings[a]
+	for _, other := range Actions {
+		if other != a && Bindings[other] == code {
+			Bindings[other] = old
 		}
 	}
-	return isRunning, isExit
+	Bindings[a] = code
+}
+
+// Apply sets bindings from settings controls, unknown key names
+// leave the binding unchanged
+func Apply(controls map[string]string) {
+	for _, a := range Actions {
+		name, ok := controls[a.key()]
+		if !ok {
+			continue
+		}
+		if code := sdl.GetScancodeFromName(name); code != sdl.SCANCODE_UNKNOWN {
+			Bindings[a] = code
+		}
+	}
+}
+
+// ValidKey reports whether SDL knows the key name
+func ValidKey(name string) bool {
+	return sdl.GetScancodeFromName(name) != sdl.SCANCODE_UNKNOWN
+}
+
+// Controls returns bindings in settings format
+func Controls() map[string]string {
+	controls := make(map[string]string)
+	for _, a := range Actions {
+		controls[a.key()] = KeyName(a)
+	}
+	return controls
+}
+
+// KeyName returns name of the key bound to the action
+func KeyName(a Action) string {
+	return sdl.GetScancodeName(Bindings[a])
+}
+
+// Down reports whether key bound to the action is held
+func Down(a Action) bool {
+	return sdl.GetKeyboardState()[Bindings[a]] == 1
+}
+
+// Pressed reports whether event is a fresh press of the key
+func Pressed(e sdl.Event, code sdl.Scancode) bool {
+	key, ok := e.(*sdl.KeyboardEvent)
+	if !ok || key.Type != sdl.KEYDOWN || key.Repeat != 0 {
+		return false
+	}
+	return key.Keysym.Scancode == code
+}
+
+// IsPause reports whether event toggles pause
+func IsPause(e sdl.Event) bool {
+	return Pressed(e, Bindings[Pause]) || Pressed(e, sdl.SCANCODE_ESCAPE)
 }
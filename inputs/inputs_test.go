@@ -0,0 +1,43 @@
+package inputs
+
+import (
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestBindSwapsDuplicates(t *testing.T) {
+	saved := make(map[Action]sdl.Scancode)
+	for a, code := range Bindings {
+		saved[a] = code
+	}
+	defer func() { Bindings = saved }()
+
+	Bind(MoveLeft, sdl.SCANCODE_SPACE)
+	if Bindings[MoveLeft] != sdl.SCANCODE_SPACE {
+		t.Errorf("left = %d, want space", Bindings[MoveLeft])
+	}
+	if Bindings[Shoot] != sdl.SCANCODE_LEFT {
+		t.Errorf("shoot = %d, want old left key", Bindings[Shoot])
+	}
+
+	Bind(Pause, sdl.SCANCODE_P)
+	seen := make(map[sdl.Scancode]Action)
+	for _, a := range Actions {
+		if other, ok := seen[Bindings[a]]; ok {
+			t.Errorf("%s and %s share a key", a, other)
+		}
+		seen[Bindings[a]] = a
+	}
+}
+
+func TestPressedIgnoresRepeat(t *testing.T) {
+	e := &sdl.KeyboardEvent{Type: sdl.KEYDOWN, Keysym: sdl.Keysym{Scancode: sdl.SCANCODE_A}}
+	if !Pressed(e, sdl.SCANCODE_A) {
+		t.Error("fresh press not seen")
+	}
+	e.Repeat = 1
+	if Pressed(e, sdl.SCANCODE_A) {
+		t.Error("repeat seen as press")
+	}
+}
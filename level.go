@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sdl_learn/logger"
+	"sdl_learn/render"
+	"sdl_learn/tilemap"
+	"sdl_learn/tilemap/tiles"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// LevelFile is the Tiled map of the playfield, built in layout is used without it
+const LevelFile = "assets/maps/level1.tmj"
+
+// Object classes of the map spawn layers
+const (
+	classPlayer = "player"
+	classUfo    = "ufo"
+)
+
+// Default enemy positions when the map has none
+var defaultUfoSpawns = []sdl.Point{
+	{X: WindowWidth/2 - 10, Y: 10},
+	{X: WindowWidth/2 - 200, Y: 100},
+	{X: WindowWidth/2 + 10, Y: 200},
+	{X: WindowWidth/2 + 200, Y: 300},
+}
+
+// windowBounds is the playfield without a map
+var windowBounds = sdl.FRect{W: float32(WindowWidth), H: float32(WindowHeight)}
+
+// level is the loaded map with its tiles
+type level struct {
+	Map   *tilemap.Map
+	Tiles *tiles.Renderer
+}
+
+// loadLevel reads map and its tilesets, nil when there is no map
+// or it is broken, problems other than a missing file are logged
+func loadLevel(l render.Loader, path string) *level {
+	m, err := tilemap.Load(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Error("unable to load map: %s", err.Error())
+		}
+		return nil
+	}
+	t, err := tiles.New(l, m)
+	if err != nil {
+		logger.Error("unable to load map tiles: %s", err.Error())
+		return nil
+	}
+	return &level{Map: m, Tiles: t}
+}
+
+// Spawns returns top left corners of the class objects, fallback is
+// used when level is nil or has no such objects
+func (lv *level) Spawns(class string, fallback []sdl.Point) []sdl.Point {
+	if lv == nil {
+		return fallback
+	}
+	objects := lv.Map.Objects(class)
+	if len(objects) == 0 {
+		return fallback
+	}
+	points := make([]sdl.Point, len(objects))
+	for i, o := range objects {
+		points[i] = sdl.Point{X: int32(o.X), Y: int32(o.Y)}
+	}
+	return points
+}
+
+// Bounds returns playfield area in pixels, the window without a map
+func (lv *level) Bounds() sdl.FRect {
+	if lv == nil {
+		return windowBounds
+	}
+	w, h := lv.Map.PixelSize()
+	return sdl.FRect{W: float32(w), H: float32(h)}
+}
+
+// Zoom returns camera zoom from the map zoom property, 1 by default
+func (lv *level) Zoom() float64 {
+	if lv == nil {
+		return 1
+	}
+	return lv.Map.Properties.Float("zoom", 1)
+}
+
+// Submit adds visible tiles to the queue
+func (lv *level) Submit(q *render.Queue, area sdl.FRect) {
+	if lv != nil {
+		lv.Tiles.Submit(q, area)
+	}
+}
+
+// Free releases tile textures
+func (lv *level) Free() {
+	if lv != nil {
+		lv.Tiles.Free()
+	}
+}
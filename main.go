@@ -1,34 +1,61 @@
 package main
 
 import (
-	"fmt"
+	"runtime"
+	"sdl_learn/audio"
+	"sdl_learn/camera"
 	"sdl_learn/gobject"
 	"sdl_learn/inputs"
+	"sdl_learn/logger"
+	"sdl_learn/render"
+	"sdl_learn/scene"
+	"sdl_learn/settings"
+	"sdl_learn/starfield"
+	"sdl_learn/starfield/background"
+	"sdl_learn/text"
 	"strconv"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-// Global consts
+// Global consts, window size and FPS come from settings,
+// WindowWidth and WindowHeight are the size of the playfield
 const (
-	FPS          uint32 = 60
-	DelayTime    uint32 = uint32(1000.0 / FPS)
-	WindowWidth  int32  = 1280
-	WindowHeight int32  = 720
-	WindowTitle         = "Game"
+	WindowWidth  int32 = 1280
+	WindowHeight int32 = 720
+	WindowTitle        = "Game"
+)
+
+// Font sizes of TTF fonts
+const (
+	FontSize      = 32
+	DebugFontSize = 14
+)
+
+// Font files, bitmap one is used when TTF is unavailable,
+// tests point them to generated fonts
+var (
+	FontFile       = "assets/font.ttf"
+	BitmapFontFile = "assets/font.fnt"
 )
 
 // Globals, maybe someday wrapped to struct but now less to type
 var (
-	win       *sdl.Window
-	rend      *sdl.Renderer
-	event     sdl.Event
-	err       error
-	isRunning = true
-	isExit    bool
+	win    *sdl.Window
+	rend   *sdl.Renderer
+	screen render.Device
+	event  sdl.Event
+	err    error
+	scenes *scene.Manager
+	sounds *audio.Manager
+	stars  *background.Background
+	font   text.Font
+	queue  *render.Queue
+	cam    *camera.Camera
+	// Milliseconds since the previous frame
+	frameDelta float64
 	// Maybe later
-	players, enemies, bullets map[string]*gobject.Gobject
-	BulletId, UfoId           int
+	BulletId, UfoId, BossId int
 )
 
 // Error checker
@@ -38,264 +65,172 @@ func perror(err error) {
 	}
 }
 
+// loadSettings reads settings file from the user config dir,
+// problems are logged and defaults are used instead
+func loadSettings() {
+	settings.ValidKey = inputs.ValidKey
+	path, err := settings.Path()
+	if err != nil {
+		logger.Error("unable to find settings dir: %s", err.Error())
+		return
+	}
+	s, err := settings.Load(path)
+	if err != nil {
+		logger.Error("settings: %s", err.Error())
+	}
+	if s != nil {
+		settings.Current = s
+	}
+	inputs.Apply(settings.Current.Controls)
+}
+
+// initGame creates everything scenes use, renderer must be ready,
+// stars seed 0 takes current time, free releases it all
+func initGame(seed int64) (free func()) {
+	// Camera is kept inside the playfield, the level sets its size
+	cam = camera.New(WindowWidth, WindowHeight)
+	cam.Bounds = windowBounds
+
+	sounds = initSound()
+	initEffects(rend)
+
+	stars, err = background.New(backgroundRenderer(), starfield.New(starfield.Config{
+		Width:       int(WindowWidth),
+		Height:      int(WindowHeight),
+		Count:       1500,
+		Layers:      3,
+		Warp:        0.1,
+		Mode:        starfield.Vertical,
+		TrailLength: 3,
+		Seed:        seed,
+	}))
+	perror(err)
+
+	var freeFont func()
+	font, freeFont, err = loadFont(rend, FontSize)
+	perror(err)
+	freeDebug := initDebug()
+
+	queue = render.NewQueue()
+
+	scenes = scene.NewManager()
+	scenes.Register(scene.Title, newTitleScene)
+	scenes.Register(scene.Options, newOptionsScene)
+	scenes.Register(scene.Playing, newPlayingScene)
+	scenes.Register(scene.Paused, newPausedScene)
+	scenes.Register(scene.GameOver, newGameOverScene)
+	scenes.Register(scene.HighScores, newHighScoresScene)
+
+	return func() {
+		freeDebug()
+		freeFont()
+		stars.Free()
+		freeEffects()
+		sounds.Close()
+	}
+}
+
+// SDL and OpenGL calls must come from the main thread
+func init() {
+	runtime.LockOSThread()
+}
+
 func main() {
+	loadSettings()
+	window := settings.Current.Window
+	delayTime := 1000 / window.FPS
+
 	// Init SDL and create window
 	err = sdl.Init(sdl.INIT_VIDEO)
 	perror(err)
+	defer sdl.Quit()
 
+	var flags uint32 = sdl.WINDOW_SHOWN | sdl.WINDOW_RESIZABLE | windowFlags()
+	if window.Fullscreen {
+		flags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
 	win, err = sdl.CreateWindow(
 		WindowTitle,
 		sdl.WINDOWPOS_CENTERED,
 		sdl.WINDOWPOS_CENTERED,
-		WindowWidth,
-		WindowHeight,
-		sdl.WINDOW_SHOWN,
+		window.Width,
+		window.Height,
+		flags,
 	)
 	perror(err)
 	defer win.Destroy()
 
-	// Create renderer
-	rend, err = sdl.CreateRenderer(win, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
-	perror(err)
-	defer rend.Destroy()
-
-	destroyed := make(map[string]int)
-	// Create player
-	player := gobject.NewGobject(
-		rend,
-		"assets/battleship.png",
-		"assets/exp.png",
-		"assets/bullet.png",
-		"player",
-		WindowWidth/2-10,
-		int32(float64(WindowHeight)*0.8),
-		WindowWidth,
-		WindowHeight,
-		true,
-	)
-	player.Destroyed = destroyed
-	ufo1 := gobject.NewGobject(
-		rend,
-		"assets/ufo.png",
-		"assets/exp.png",
-		"",
-		"ufo1",
-		WindowWidth/2-10,
-		int32(10),
-		WindowWidth,
-		WindowHeight,
-		true,
-	)
-	ufo2 := gobject.NewGobject(
-		rend,
-		"assets/ufo.png",
-		"assets/exp.png",
-		"",
-		"ufo2",
-		WindowWidth/2-200,
-		int32(100),
-		WindowWidth,
-		WindowHeight,
-		true,
-	)
-	ufo3 := gobject.NewGobject(
-		rend,
-		"assets/ufo.png",
-		"assets/exp.png",
-		"",
-		"ufo3",
-		WindowWidth/2+10,
-		int32(200),
-		WindowWidth,
-		WindowHeight,
-		true,
-	)
-	ufo4 := gobject.NewGobject(
-		rend,
-		"assets/ufo.png",
-		"assets/exp.png",
-		"",
-		"ufo4",
-		WindowWidth/2+200,
-		int32(300),
-		WindowWidth,
-		WindowHeight,
-		true,
-	)
-
-	// Init gameObjects map
-	players = make(map[string]*gobject.Gobject)
-	players[player.Id] = player
-	enemies := make(map[string]*gobject.Gobject)
-	enemies[ufo1.Id] = ufo1
-	enemies[ufo2.Id] = ufo2
-	enemies[ufo3.Id] = ufo3
-	enemies[ufo4.Id] = ufo4
+	// Create renderer, SDL or OpenGL depending on settings
+	initRenderer()
+	defer freeRenderer()
+	initPost()
+	setupView()
 
-	bullets := make(map[string]*gobject.Gobject)
+	freeGame := initGame(0)
+	defer freeGame()
+	initRecorders()
 
-	manager := gobject.NewManager(player, rend, enemies, bullets)
+	scenes.Reset(scene.Title)
+	scenes.Update()
+	defer saveSettings()
 
-	var left int32
-	var right int32
-	var shot bool
-
-startGame:
 	// Game loop
-	for isRunning {
-		score := 0
-		if len(player.Destroyed) > 0 {
-			for _, v := range player.Destroyed {
-				score += v
-			}
-		}
-		player.Score = score
-		if player.Score%400 == 0 && len(manager.Enemies) == 0 {
-			for i := 1; i < 5; i++ {
-				newUfo := NewUfo(rend, int32(i*200), int32(i*100))
-				manager.Enemies[newUfo.Id] = newUfo
-			}
-		}
+	lastFrameTime := sdl.GetTicks()
+	for !scenes.Done() {
 		frameStartTime := sdl.GetTicks()
-
-		isRunning, isExit = inputs.Listen(isRunning)
-		if !isRunning {
-			goto paused
-		}
+		frameDelta = float64(frameStartTime - lastFrameTime)
+		sounds.Music.Update(frameDelta)
+		stars.Update(frameDelta * 60 / 1000)
+		lastFrameTime = frameStartTime
 
 		// Handle event queue
 		for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-			switch t := event.(type) {
-			case *sdl.QuitEvent:
-				fmt.Println(t)
-				isExit = true
-				isRunning = false
-				break
+			if _, ok := event.(*sdl.QuitEvent); ok {
+				scenes.Quit()
 			}
+			if handleDisplayEvent(event) || handleCaptureEvent(event) {
+				continue
+			}
+			scenes.HandleEvent(event)
 		}
-
-		// Clear screen
-		rend.SetDrawColor(0, 100, 155, 0)
-		rend.Clear()
-
-		if player.IsMoving {
-			player.Draw(rend)
-			player.Update(rend)
-			shot = manager.ScanShoot()
-		} else {
-			player.Draw(rend)
-			loss(player)
-		}
-
-		if shot {
-			bullet := NewBullet(rend, player.X, player.Y)
-			manager.Bullets[bullet.Id] = bullet
+		if scenes.Done() {
+			break
 		}
 
-		if left < WindowWidth/4 {
-			for _, val := range enemies {
-				if val.IsMoving && val.X > 100 {
-					val.Draw(rend)
-					val.LeftMoving(rend, player)
-					left++
-				} else {
-					val.Draw(rend)
-				}
-			}
-		} else if left >= WindowWidth/4 && right < WindowWidth-100 {
-			for _, val := range enemies {
-				if val.IsMoving && val.X < WindowWidth-100 {
-					val.Draw(rend)
-					val.RightMoving(rend, player)
-					right++
-				} else {
-					val.Draw(rend)
-				}
-			}
-		} else {
-			if right >= WindowWidth-100 && left >= WindowWidth/4 {
-				left, right = -WindowWidth, 0
-			}
-		}
+		scenes.Update()
 
-		for key, val := range manager.Bullets {
-			if val.Y > 0 && val.IsMoving {
-				val.UpMoving(manager.R, manager.Enemies, manager.Bullets, manager.PlayerObj)
-				val.Draw(rend)
-			} else if val.Y <= 0 || !val.IsMoving {
-				val.Draw(rend)
-				val.Free()
-				delete(manager.Bullets, key)
-			}
-		}
-		rend.Present()
+		drawFrame()
 
 		// If too fast add delay
 		frameTime := sdl.GetTicks() - frameStartTime
-		if frameTime < DelayTime {
-			sdl.Delay(uint32(DelayTime - frameTime))
-		}
-
-	} // End of isRunning
-
-paused:
-	for !isExit {
-		isRunning, isExit = inputs.Listen(isRunning)
-		if isExit {
-			break
-		}
-		if isRunning {
-			goto startGame
+		if frameTime < delayTime {
+			sdl.Delay(delayTime - frameTime)
 		}
-		isRunning = showPause("PAUSED")
-	}
-	if isExit {
-		for _, val := range enemies {
-			val.Free()
-		}
-		player.Free()
-		sdl.Quit()
 	}
 }
 
-func showPause(message string) bool {
-	var resp bool
-	buttons := []sdl.MessageBoxButtonData{
-		{Flags: sdl.MESSAGEBOX_BUTTON_ESCAPEKEY_DEFAULT, ButtonID: 0, Text: "cancel " + message},
-	}
-
-	colorScheme := sdl.MessageBoxColorScheme{
-		Colors: [5]sdl.MessageBoxColor{
-			sdl.MessageBoxColor{R: 255, G: 0, B: 0},
-			sdl.MessageBoxColor{R: 0, G: 255, B: 0},
-			sdl.MessageBoxColor{R: 255, G: 255, B: 0},
-			sdl.MessageBoxColor{R: 0, G: 0, B: 255},
-			sdl.MessageBoxColor{R: 255, G: 0, B: 255},
-		},
-	}
-
-	messageboxdata := sdl.MessageBoxData{
-		Flags:       sdl.MESSAGEBOX_INFORMATION,
-		Window:      nil,
-		Title:       message,
-		Message:     "",
-		Buttons:     buttons,
-		ColorScheme: &colorScheme,
-	}
-
-	buttonid, _ := sdl.ShowMessageBox(&messageboxdata)
-
-	if buttonid != 1 {
-		resp = true
-	}
-	return resp
+func NewPlayer(l render.Loader) *gobject.Gobject {
+	player := gobject.NewGobject(
+		l,
+		"assets/battleship.png",
+		"assets/exp.png",
+		"assets/bullet.png",
+		"player",
+		WindowWidth/2-10,
+		int32(float64(WindowHeight)*0.8),
+		WindowWidth,
+		WindowHeight,
+		true,
+	)
+	player.Layer = render.LayerPlayer
+	return player
 }
 
-func NewBullet(r *sdl.Renderer, x, y int32) *gobject.Gobject {
+func NewBullet(l render.Loader, x, y int32) *gobject.Gobject {
 	BulletId += 1
 	strId := "bullet" + strconv.Itoa(BulletId)
-	return gobject.NewGobject(
-		r,
+	bullet := gobject.NewGobject(
+		l,
 		"assets/bullet.png",
 		"",
 		"",
@@ -306,13 +241,15 @@ func NewBullet(r *sdl.Renderer, x, y int32) *gobject.Gobject {
 		WindowHeight,
 		true,
 	)
+	bullet.Layer = render.LayerBullets
+	return bullet
 }
 
-func NewUfo(r *sdl.Renderer, x, y int32) *gobject.Gobject {
+func NewUfo(l render.Loader, x, y int32) *gobject.Gobject {
 	UfoId += 1
-	strId := "bullet" + strconv.Itoa(UfoId)
-	return gobject.NewGobject(
-		rend,
+	strId := "ufo" + strconv.Itoa(UfoId)
+	ufo := gobject.NewGobject(
+		l,
 		"assets/ufo.png",
 		"assets/exp.png",
 		"",
@@ -323,24 +260,29 @@ func NewUfo(r *sdl.Renderer, x, y int32) *gobject.Gobject {
 		WindowHeight,
 		true,
 	)
+	ufo.Layer = render.LayerEnemies
+	return ufo
 }
 
-func loss(player *gobject.Gobject) {
-	_ = showPause("LOSS")
-	for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-		switch t := event.(type) {
-		case *sdl.QuitEvent:
-			fmt.Println(t)
-			isExit = true
-			isRunning = false
-			break
-		}
-	}
-	if isExit {
-		for _, val := range enemies {
-			val.Free()
-		}
-		player.Free()
-		sdl.Quit()
-	}
+// NewBoss creates ufo drawn and hit twice as big
+func NewBoss(l render.Loader, x, y int32) *gobject.Gobject {
+	BossId += 1
+	strId := "boss" + strconv.Itoa(BossId)
+	boss := gobject.NewGobject(
+		l,
+		"assets/ufo.png",
+		"assets/exp.png",
+		"",
+		strId,
+		x,
+		y,
+		WindowWidth,
+		WindowHeight,
+		true,
+	)
+	boss.Width *= 2
+	boss.Height *= 2
+	boss.Layer = render.LayerEnemies
+	boss.HP = bossHP
+	return boss
 }
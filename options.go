@@ -0,0 +1,158 @@
+package main
+
+import (
+	"sdl_learn/inputs"
+	"sdl_learn/logger"
+	"sdl_learn/scene"
+	"sdl_learn/settings"
+	"sdl_learn/ui"
+	"strconv"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const volumeStep = 10
+
+// saveSettings writes current settings to the user config dir
+func saveSettings() {
+	settings.Current.Controls = inputs.Controls()
+	path, err := settings.Path()
+	if err == nil {
+		err = settings.Save(path, settings.Current)
+	}
+	if err != nil {
+		logger.Error("unable to save settings: %s", err.Error())
+	}
+}
+
+// Rows of the options screen, key bindings go between window and difficulty
+const (
+	optionVolume = iota
+	optionWindow
+	optionScaling
+	optionKeys
+)
+
+// optionsScene changes volume, window mode, controls and difficulty
+type optionsScene struct {
+	menu *ui.Menu
+	// Action waiting for a new key, -1 if none
+	rebinding inputs.Action
+}
+
+func newOptionsScene() scene.Scene {
+	return &optionsScene{
+		menu:      ui.NewMenu("OPTIONS"),
+		rebinding: -1,
+	}
+}
+
+func (s *optionsScene) optionDifficulty() int {
+	return optionKeys + len(inputs.Actions)
+}
+
+func (s *optionsScene) optionBack() int {
+	return s.optionDifficulty() + 1
+}
+
+// refresh builds menu items from current values
+func (s *optionsScene) refresh() {
+	window := "Windowed"
+	if settings.Current.Window.Fullscreen {
+		window = "Fullscreen"
+	}
+	scaling := "Letterbox"
+	if settings.Current.Window.IntegerScale {
+		scaling = "Integer"
+	}
+	items := []string{
+		"Volume: " + strconv.Itoa(settings.Current.Audio.Master) + "%",
+		"Window: " + window,
+		"Scaling: " + scaling,
+	}
+	for _, a := range inputs.Actions {
+		key := inputs.KeyName(a)
+		if a == s.rebinding {
+			key = "press a key..."
+		}
+		items = append(items, "Key "+a.String()+": "+key)
+	}
+	items = append(items, "Difficulty: "+settings.Current.Gameplay.Difficulty.String(), "Back")
+	s.menu.Items = items
+}
+
+func (s *optionsScene) Enter() {
+	s.refresh()
+}
+
+func (s *optionsScene) Exit() {
+	saveSettings()
+}
+
+func (s *optionsScene) HandleEvent(e sdl.Event) {
+	defer s.refresh()
+
+	if s.rebinding >= 0 {
+		// Held key repeats the event which started rebinding
+		if key, ok := e.(*sdl.KeyboardEvent); ok && key.Type == sdl.KEYDOWN && key.Repeat == 0 {
+			if key.Keysym.Scancode != sdl.SCANCODE_ESCAPE {
+				inputs.Bind(s.rebinding, key.Keysym.Scancode)
+			}
+			s.rebinding = -1
+		}
+		return
+	}
+
+	if inputs.Pressed(e, sdl.SCANCODE_ESCAPE) {
+		scenes.Pop()
+		return
+	}
+
+	step := 0
+	if inputs.Pressed(e, sdl.SCANCODE_LEFT) {
+		step = -1
+	} else if inputs.Pressed(e, sdl.SCANCODE_RIGHT) {
+		step = 1
+	}
+	chosen := s.menu.HandleEvent(e)
+	selected := s.menu.Selected
+	if step == 0 && chosen < 0 {
+		return
+	}
+
+	switch {
+	case selected == optionVolume:
+		if step == 0 {
+			step = 1
+		}
+		audio := &settings.Current.Audio
+		audio.Master = min(max(audio.Master+step*volumeStep, 0), 100)
+		applyVolume(sounds)
+	case selected == optionWindow:
+		setFullscreen(!settings.Current.Window.Fullscreen)
+	case selected == optionScaling:
+		settings.Current.Window.IntegerScale = !settings.Current.Window.IntegerScale
+		setupView()
+	case selected < s.optionDifficulty():
+		if chosen >= 0 {
+			s.rebinding = inputs.Actions[selected-optionKeys]
+		}
+	case selected == s.optionDifficulty():
+		gameplay := &settings.Current.Gameplay
+		if step < 0 {
+			gameplay.Difficulty = gameplay.Difficulty.Prev()
+		} else {
+			gameplay.Difficulty = gameplay.Difficulty.Next()
+		}
+	case selected == s.optionBack():
+		if chosen >= 0 {
+			scenes.Pop()
+		}
+	}
+}
+
+func (s *optionsScene) Update() {}
+
+func (s *optionsScene) Draw(r *sdl.Renderer) {
+	s.menu.Draw(r, font)
+}
@@ -0,0 +1,76 @@
+package particles
+
+import (
+	"image/color"
+	"sort"
+)
+
+// Key is a curve value at the moment of particle life from 0 to 1
+type Key struct {
+	T, V float64
+}
+
+// Curve is a value changing over particle life, linear between keys
+type Curve []Key
+
+// Const returns curve with the same value over whole life
+func Const(v float64) Curve {
+	return Curve{{0, v}}
+}
+
+// Line returns curve going from one value to another
+func Line(from, to float64) Curve {
+	return Curve{{0, from}, {1, to}}
+}
+
+// At returns value at life moment t, keys must be sorted by T
+func (c Curve) At(t float64) float64 {
+	if len(c) == 0 {
+		return 0
+	}
+	if t <= c[0].T {
+		return c[0].V
+	}
+	i := sort.Search(len(c), func(i int) bool { return c[i].T >= t })
+	if i == len(c) {
+		return c[len(c)-1].V
+	}
+	a, b := c[i-1], c[i]
+	if b.T == a.T {
+		return b.V
+	}
+	return a.V + (b.V-a.V)*(t-a.T)/(b.T-a.T)
+}
+
+// ColorKey is a color at the moment of particle life
+type ColorKey struct {
+	T float64
+	C color.NRGBA
+}
+
+// ColorCurve is a color changing over particle life, alpha is taken
+// from the alpha curve so only RGB is used
+type ColorCurve []ColorKey
+
+// At returns color at life moment t, keys must be sorted by T
+func (c ColorCurve) At(t float64) color.NRGBA {
+	if len(c) == 0 {
+		return color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	if t <= c[0].T {
+		return c[0].C
+	}
+	i := sort.Search(len(c), func(i int) bool { return c[i].T >= t })
+	if i == len(c) {
+		return c[len(c)-1].C
+	}
+	a, b := c[i-1], c[i]
+	k := 1.0
+	if b.T != a.T {
+		k = (t - a.T) / (b.T - a.T)
+	}
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*k)
+	}
+	return color.NRGBA{R: lerp(a.C.R, b.C.R), G: lerp(a.C.G, b.C.G), B: lerp(a.C.B, b.C.B), A: lerp(a.C.A, b.C.A)}
+}
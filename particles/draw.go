@@ -0,0 +1,73 @@
+package particles
+
+import (
+	"image/color"
+	"math"
+	"sdl_learn/raster"
+	"sdl_learn/render"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Size of the generated particle sprite
+const spriteSize = 32
+
+// Renderer draws particles as soft round sprites
+type Renderer struct {
+	sprite *sdl.Texture
+}
+
+// NewRenderer creates the particle sprite, a white disc fading to its edge
+func NewRenderer(r *sdl.Renderer) (*Renderer, error) {
+	c := raster.New(spriteSize, spriteSize)
+	c.Mode = raster.Replace
+	center := float64(spriteSize-1) / 2
+	for y := 0; y < spriteSize; y++ {
+		for x := 0; x < spriteSize; x++ {
+			d := math.Hypot(float64(x)-center, float64(y)-center) / (spriteSize / 2)
+			a := math.Max(1-d, 0)
+			c.Set(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: uint8(a * a * 255)})
+		}
+	}
+	texture, err := r.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STATIC, spriteSize, spriteSize)
+	if err != nil {
+		return nil, err
+	}
+	texture.Update(nil, unsafe.Pointer(&c.Pix[0]), spriteSize*4)
+	return &Renderer{sprite: texture}, nil
+}
+
+// Draw renders live particles of the system moved by the transform
+func (pr *Renderer) Draw(r *sdl.Renderer, s *System, view render.Transform) {
+	if view == nil {
+		view = render.Identity{}
+	}
+	s.Each(func(p *Particle, t float64) {
+		cfg := p.config
+		size := cfg.Size.At(t)
+		if size <= 0 {
+			return
+		}
+		col := cfg.Color.At(t)
+		alpha := 1.0
+		if len(cfg.Alpha) > 0 {
+			alpha = cfg.Alpha.At(t)
+		}
+		var mode sdl.BlendMode = sdl.BLENDMODE_BLEND
+		if cfg.Additive {
+			mode = sdl.BLENDMODE_ADD
+		}
+		pr.sprite.SetBlendMode(mode)
+		pr.sprite.SetColorMod(col.R, col.G, col.B)
+		pr.sprite.SetAlphaMod(uint8(math.Min(math.Max(alpha, 0), 1) * 255))
+		half := size / 2
+		dst := view.FRect(sdl.FRect{X: float32(p.X - half), Y: float32(p.Y - half), W: float32(size), H: float32(size)})
+		r.CopyF(pr.sprite, nil, &dst)
+	})
+}
+
+// Free resources
+func (pr *Renderer) Free() {
+	pr.sprite.Destroy()
+}
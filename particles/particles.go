@@ -0,0 +1,179 @@
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Config describes particles of an emitter
+type Config struct {
+	// Particles emitted at once by Burst
+	Burst int
+	// Particles per second of a continuous emitter
+	Rate float64
+	// Life in seconds
+	LifeMin, LifeMax float64
+	// Start speed in pixels per second
+	SpeedMin, SpeedMax float64
+	// Direction in radians, 0 is right and Pi/2 is down,
+	// particles spread by Spread to both sides
+	Angle, Spread float64
+	// Vertical acceleration in pixels per second squared
+	Gravity float64
+	// Size in pixels, alpha from 0 to 1 and color over life
+	Size  Curve
+	Alpha Curve
+	Color ColorCurve
+	// Additive particles brighten what is below them
+	Additive bool
+}
+
+// Particle is a single pooled point
+type Particle struct {
+	X, Y, VX, VY float64
+	Age, Life    float64
+	config       *Config
+}
+
+// Emitter spawns particles at its position, position is taken from
+// Follow when set so emitter can be attached to a game object
+type Emitter struct {
+	Config *Config
+	X, Y   float64
+	Follow func() (x, y float64, ok bool)
+	Active bool
+	// Fraction of particle left from the previous update
+	carry float64
+}
+
+// System owns the particle pool and emitters, it is safe to
+// call Burst from other goroutines
+type System struct {
+	mu        sync.Mutex
+	particles []Particle
+	alive     int
+	emitters  []*Emitter
+	rng       *rand.Rand
+}
+
+// NewSystem creates pool for up to capacity live particles,
+// when pool is full new particles are dropped
+func NewSystem(capacity int) *System {
+	return &System{
+		particles: make([]Particle, capacity),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Attach adds continuous emitter
+func (s *System) Attach(e *Emitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.Active = true
+	s.emitters = append(s.emitters, e)
+}
+
+// Detach removes emitter, its live particles keep flying
+func (s *System) Detach(e *Emitter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, other := range s.emitters {
+		if other == e {
+			s.emitters = append(s.emitters[:i], s.emitters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Burst emits config.Burst particles at the point
+func (s *System) Burst(config *Config, x, y float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < config.Burst; i++ {
+		s.spawn(config, x, y)
+	}
+}
+
+// Clear removes all particles and emitters
+func (s *System) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alive = 0
+	s.emitters = nil
+}
+
+// Alive returns number of live particles
+func (s *System) Alive() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.alive
+}
+
+// spawn takes particle from the pool, lock must be held
+func (s *System) spawn(config *Config, x, y float64) {
+	if s.alive == len(s.particles) {
+		return
+	}
+	angle := config.Angle + (s.rng.Float64()*2-1)*config.Spread
+	speed := between(s.rng, config.SpeedMin, config.SpeedMax)
+	s.particles[s.alive] = Particle{
+		X:      x,
+		Y:      y,
+		VX:     math.Cos(angle) * speed,
+		VY:     math.Sin(angle) * speed,
+		Life:   math.Max(between(s.rng, config.LifeMin, config.LifeMax), 0.001),
+		config: config,
+	}
+	s.alive++
+}
+
+func between(rng *rand.Rand, min, max float64) float64 {
+	return min + rng.Float64()*(max-min)
+}
+
+// Update emits from continuous emitters and moves particles, dt is in seconds
+func (s *System) Update(dt float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.emitters {
+		if e.Follow != nil {
+			x, y, ok := e.Follow()
+			e.Active = ok
+			e.X, e.Y = x, y
+		}
+		if !e.Active {
+			e.carry = 0
+			continue
+		}
+		e.carry += e.Config.Rate * dt
+		for ; e.carry >= 1; e.carry-- {
+			s.spawn(e.Config, e.X, e.Y)
+		}
+	}
+	// Dead particles are swapped with the last live one so pool stays packed
+	for i := 0; i < s.alive; {
+		p := &s.particles[i]
+		p.Age += dt
+		if p.Age >= p.Life {
+			s.alive--
+			s.particles[i] = s.particles[s.alive]
+			continue
+		}
+		p.VY += p.config.Gravity * dt
+		p.X += p.VX * dt
+		p.Y += p.VY * dt
+		i++
+	}
+}
+
+// Each calls f for every live particle with its life moment from 0 to 1
+func (s *System) Each(f func(p *Particle, t float64)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < s.alive; i++ {
+		p := &s.particles[i]
+		f(p, p.Age/p.Life)
+	}
+}
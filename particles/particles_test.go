@@ -0,0 +1,120 @@
+package particles
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestCurve(t *testing.T) {
+	// Two keys at 0.6 make a jump
+	c := Curve{{0.2, 10}, {0.6, 30}, {0.6, 50}, {1, 0}}
+	for _, tt := range []struct{ t, want float64 }{
+		{-1, 10}, {0, 10}, {0.2, 10},
+		{0.4, 20}, {0.6, 30}, {0.8, 25},
+		{1, 0}, {2, 0},
+	} {
+		if got := c.At(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("At(%v) = %v want %v", tt.t, got, tt.want)
+		}
+	}
+	if Const(3).At(0.7) != 3 || Line(2, 4).At(0.5) != 3 || (Curve{}).At(0.5) != 0 {
+		t.Error("helper curves")
+	}
+}
+
+func TestColorCurve(t *testing.T) {
+	c := ColorCurve{{0.5, color.NRGBA{A: 255}}, {1, color.NRGBA{200, 100, 50, 255}}}
+	for _, tt := range []struct {
+		t    float64
+		want color.NRGBA
+	}{
+		{0, color.NRGBA{A: 255}},
+		{0.5, color.NRGBA{A: 255}},
+		{0.75, color.NRGBA{100, 50, 25, 255}},
+		{1, color.NRGBA{200, 100, 50, 255}},
+		{3, color.NRGBA{200, 100, 50, 255}},
+	} {
+		if got := c.At(tt.t); got != tt.want {
+			t.Errorf("At(%v) = %v want %v", tt.t, got, tt.want)
+		}
+	}
+	if got := (ColorCurve{}).At(0.5); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("empty curve %v", got)
+	}
+}
+
+func TestBurstFullPool(t *testing.T) {
+	s := NewSystem(5)
+	config := &Config{Burst: 3, LifeMin: 1, LifeMax: 1}
+	s.Burst(config, 0, 0)
+	s.Burst(config, 0, 0)
+	if n := s.Alive(); n != 5 {
+		t.Errorf("two bursts of 3 in pool of 5 left %d alive", n)
+	}
+}
+
+func TestUpdateKillsExpired(t *testing.T) {
+	s := NewSystem(8)
+	short := &Config{Burst: 1, LifeMin: 0.5, LifeMax: 0.5}
+	long := &Config{Burst: 1, LifeMin: 2, LifeMax: 2}
+	// Interleaved so dead particles sit between live ones
+	for i := 0; i < 3; i++ {
+		s.Burst(short, 0, 0)
+		s.Burst(long, 0, 0)
+	}
+	s.Update(1)
+	if n := s.Alive(); n != 3 {
+		t.Fatalf("%d alive want 3", n)
+	}
+	// Live particles are packed at the front of the pool, each aged once
+	for i, p := range s.particles[:s.alive] {
+		if p.config != long || p.Age != 1 {
+			t.Errorf("particle %d has life %v, age %v", i, p.Life, p.Age)
+		}
+	}
+	s.Update(1)
+	if n := s.Alive(); n != 0 {
+		t.Errorf("%d alive after their life", n)
+	}
+}
+
+func TestMotion(t *testing.T) {
+	s := NewSystem(1)
+	// No spread and equal limits take the randomness out
+	config := &Config{Burst: 1, LifeMin: 10, LifeMax: 10, SpeedMin: 10, SpeedMax: 10, Gravity: 20}
+	s.Burst(config, 1, 2)
+	s.Update(0.5)
+	s.Update(0.5)
+	var got Particle
+	var life float64
+	s.Each(func(p *Particle, t float64) {
+		got, life = *p, t
+	})
+	// Velocity is updated before the position
+	want := Particle{X: 11, Y: 17, VX: 10, VY: 20, Age: 1, Life: 10}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if !near(got.X, want.X) || !near(got.Y, want.Y) || !near(got.VX, want.VX) || !near(got.VY, want.VY) || got.Age != want.Age {
+		t.Errorf("particle %+v want %+v", got, want)
+	}
+	if life != 0.1 {
+		t.Errorf("life moment %v want 0.1", life)
+	}
+}
+
+func TestEmitterRate(t *testing.T) {
+	s := NewSystem(10)
+	e := &Emitter{Config: &Config{Rate: 10, LifeMin: 5, LifeMax: 5}}
+	s.Attach(e)
+	s.Update(0.25)
+	s.Update(0.25)
+	// Half particles carry over between updates
+	if n := s.Alive(); n != 5 {
+		t.Errorf("%d alive after half a second at 10 per second", n)
+	}
+	e.Follow = func() (float64, float64, bool) { return 0, 0, false }
+	s.Update(1)
+	if n := s.Alive(); n != 5 {
+		t.Errorf("inactive emitter spawned, %d alive", n)
+	}
+}
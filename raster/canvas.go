@@ -0,0 +1,199 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+)
+
+// BlendMode is how drawn color is combined with the canvas
+type BlendMode int
+
+const (
+	// Replace overwrites pixels
+	Replace BlendMode = iota
+	// Blend mixes by source alpha
+	Blend
+	// Add adds source scaled by its alpha, good for glow
+	Add
+	// Multiply darkens by source color scaled by its alpha
+	Multiply
+)
+
+// Canvas is a straight alpha RGBA buffer, bytes go R, G, B, A
+// which is the layout of SDL PIXELFORMAT_ABGR8888 textures
+type Canvas struct {
+	Pix           []byte
+	Width, Height int
+	// Drawing outside Clip is ignored
+	Clip image.Rectangle
+	Mode BlendMode
+}
+
+// New creates transparent canvas
+func New(width, height int) *Canvas {
+	return Wrap(make([]byte, width*height*4), width, height)
+}
+
+// Wrap creates canvas over existing buffer of width*height*4 bytes
+func Wrap(pix []byte, width, height int) *Canvas {
+	return &Canvas{
+		Pix:    pix,
+		Width:  width,
+		Height: height,
+		Clip:   image.Rect(0, 0, width, height),
+		Mode:   Blend,
+	}
+}
+
+// Bounds returns the whole canvas rectangle
+func (c *Canvas) Bounds() image.Rectangle {
+	return image.Rect(0, 0, c.Width, c.Height)
+}
+
+// SetClip limits drawing to the rectangle inside the canvas
+func (c *Canvas) SetClip(r image.Rectangle) {
+	c.Clip = r.Intersect(c.Bounds())
+}
+
+// ResetClip allows drawing on the whole canvas
+func (c *Canvas) ResetClip() {
+	c.Clip = c.Bounds()
+}
+
+// Image returns image sharing the canvas pixels
+func (c *Canvas) Image() *image.NRGBA {
+	return &image.NRGBA{
+		Pix:    c.Pix,
+		Stride: c.Width * 4,
+		Rect:   c.Bounds(),
+	}
+}
+
+// Clear fills clip rectangle with the color ignoring blend mode
+func (c *Canvas) Clear(col color.NRGBA) {
+	for y := c.Clip.Min.Y; y < c.Clip.Max.Y; y++ {
+		i := c.offset(c.Clip.Min.X, y)
+		for x := c.Clip.Min.X; x < c.Clip.Max.X; x++ {
+			c.Pix[i], c.Pix[i+1], c.Pix[i+2], c.Pix[i+3] = col.R, col.G, col.B, col.A
+			i += 4
+		}
+	}
+}
+
+func (c *Canvas) offset(x, y int) int {
+	return (y*c.Width + x) * 4
+}
+
+// At returns pixel color, outside of canvas is transparent
+func (c *Canvas) At(x, y int) color.NRGBA {
+	if !(image.Point{x, y}).In(c.Bounds()) {
+		return color.NRGBA{}
+	}
+	i := c.offset(x, y)
+	return color.NRGBA{R: c.Pix[i], G: c.Pix[i+1], B: c.Pix[i+2], A: c.Pix[i+3]}
+}
+
+// Set draws pixel with the current blend mode
+func (c *Canvas) Set(x, y int, col color.NRGBA) {
+	c.plot(x, y, col, 255)
+}
+
+// plot draws pixel with coverage from 0 to 255 scaling its alpha
+func (c *Canvas) plot(x, y int, col color.NRGBA, coverage uint32) {
+	if !(image.Point{x, y}).In(c.Clip) {
+		return
+	}
+	i := c.offset(x, y)
+	d := c.Pix[i : i+4 : i+4]
+	a := uint32(col.A) * coverage / 255
+	switch c.Mode {
+	case Replace:
+		if coverage == 255 {
+			d[0], d[1], d[2], d[3] = col.R, col.G, col.B, col.A
+			return
+		}
+		// Partial coverage moves the pixel towards the source
+		composite(d, col.R, col.G, col.B, a, 255-coverage)
+	case Blend:
+		composite(d, col.R, col.G, col.B, a, 255-a)
+	case Add:
+		// Sum premultiplied colors, alpha saturates
+		da := uint32(d[3])
+		outA := min(a+da, 255)
+		if outA == 0 {
+			return
+		}
+		d[0] = addChannel(d[0], col.R, da, a, outA)
+		d[1] = addChannel(d[1], col.G, da, a, outA)
+		d[2] = addChannel(d[2], col.B, da, a, outA)
+		d[3] = byte(outA)
+	case Multiply:
+		// Where the canvas is transparent the source shows as is
+		da := uint32(d[3])
+		composite(d,
+			multiplyChannel(d[0], col.R, da),
+			multiplyChannel(d[1], col.G, da),
+			multiplyChannel(d[2], col.B, da),
+			a, 255-a)
+	}
+}
+
+// composite mixes source of alpha sa over the pixel kept with weight dw,
+// both from 0 to 255, and stores the straight alpha result
+func composite(d []byte, r, g, b byte, sa, dw uint32) {
+	da := uint32(d[3]) * dw
+	outA := sa*255 + da
+	if outA == 0 {
+		d[0], d[1], d[2], d[3] = 0, 0, 0, 0
+		return
+	}
+	channel := func(dst, src byte) byte {
+		return byte((uint32(src)*sa*255 + uint32(dst)*da + outA/2) / outA)
+	}
+	d[0], d[1], d[2] = channel(d[0], r), channel(d[1], g), channel(d[2], b)
+	d[3] = byte((outA + 127) / 255)
+}
+
+// addChannel sums premultiplied dst and src and returns straight color
+func addChannel(dst, src byte, da, sa, outA uint32) byte {
+	sum := uint32(dst)*da + uint32(src)*sa
+	return byte(min((sum+outA/2)/outA, 255))
+}
+
+// multiplyChannel returns src multiplied by dst as far as dst is opaque
+func multiplyChannel(dst, src byte, da uint32) byte {
+	s, d := uint32(src), uint32(dst)
+	return byte(((255-da)*s + da*d*s/255 + 127) / 255)
+}
+
+// Equal reports whether both canvases have the same size and pixels
+func (c *Canvas) Equal(other *Canvas) bool {
+	count, _ := c.Diff(other, nil)
+	return count == 0
+}
+
+// Diff counts pixels differing from other and returns the largest
+// channel difference, when diff is not nil differing pixels are
+// painted red on it and others are kept; sizes must match
+func (c *Canvas) Diff(other *Canvas, diff *Canvas) (int, int) {
+	if c.Width != other.Width || c.Height != other.Height {
+		return c.Width * c.Height, 255
+	}
+	count, worst := 0, 0
+	for i := 0; i < len(c.Pix); i += 4 {
+		delta := 0
+		for k := 0; k < 4; k++ {
+			d := int(c.Pix[i+k]) - int(other.Pix[i+k])
+			delta = max(delta, d, -d)
+		}
+		if delta == 0 {
+			continue
+		}
+		count++
+		worst = max(worst, delta)
+		if diff != nil {
+			diff.Pix[i], diff.Pix[i+1], diff.Pix[i+2], diff.Pix[i+3] = 255, 0, 0, 255
+		}
+	}
+	return count, worst
+}
@@ -0,0 +1,322 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPlotModes(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 128}
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	tests := []struct {
+		name     string
+		mode     BlendMode
+		dst, src color.NRGBA
+		coverage uint32
+		want     color.NRGBA
+	}{
+		{"blend onto transparent", Blend, color.NRGBA{}, red, 255, red},
+		{"blend onto opaque", Blend, white, red, 255, color.NRGBA{255, 127, 127, 255}},
+		{"blend onto translucent", Blend, color.NRGBA{0, 0, 255, 128}, red, 255, color.NRGBA{170, 0, 85, 192}},
+		{"blend transparent", Blend, color.NRGBA{}, color.NRGBA{R: 255}, 255, color.NRGBA{}},
+		{"blend coverage", Blend, color.NRGBA{}, color.NRGBA{R: 255, A: 255}, 128, red},
+		{"replace", Replace, white, color.NRGBA{10, 20, 30, 40}, 255, color.NRGBA{10, 20, 30, 40}},
+		{"replace coverage", Replace, color.NRGBA{0, 0, 255, 255}, color.NRGBA{R: 255, A: 255}, 128, color.NRGBA{128, 0, 127, 255}},
+		{"add onto transparent", Add, color.NRGBA{}, color.NRGBA{200, 100, 0, 128}, 255, color.NRGBA{200, 100, 0, 128}},
+		{"add onto opaque", Add, color.NRGBA{100, 100, 100, 255}, color.NRGBA{R: 200, A: 128}, 255, color.NRGBA{200, 100, 100, 255}},
+		{"add saturates", Add, color.NRGBA{200, 0, 0, 200}, color.NRGBA{200, 0, 0, 200}, 255, color.NRGBA{255, 0, 0, 255}},
+		{"multiply onto opaque", Multiply, color.NRGBA{200, 100, 50, 255}, color.NRGBA{128, 255, 0, 255}, 255, color.NRGBA{100, 100, 0, 255}},
+		{"multiply onto transparent", Multiply, color.NRGBA{}, color.NRGBA{128, 255, 0, 255}, 255, color.NRGBA{128, 255, 0, 255}},
+		{"multiply translucent", Multiply, white, color.NRGBA{A: 128}, 255, color.NRGBA{127, 127, 127, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(2, 1)
+			c.Clear(tt.dst)
+			c.Mode = tt.mode
+			c.plot(0, 0, tt.src, tt.coverage)
+			want := New(2, 1)
+			want.Clear(tt.dst)
+			want.Pix[0], want.Pix[1], want.Pix[2], want.Pix[3] = tt.want.R, tt.want.G, tt.want.B, tt.want.A
+			if !c.Equal(want) {
+				t.Errorf("got %v want %v", c.Pix, want.Pix)
+			}
+		})
+	}
+}
+
+// lineWalk is the plain Bresenham loop relying on Set to drop off canvas pixels
+func lineWalk(c *Canvas, x0, y0, x1, y1 int, col color.NRGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		c.Set(x0, y0, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func TestLineClip(t *testing.T) {
+	col := color.NRGBA{R: 255, A: 255}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x0, y0 := rng.Intn(80)-40, rng.Intn(80)-40
+		x1, y1 := rng.Intn(80)-40, rng.Intn(80)-40
+		got, want := New(16, 12), New(16, 12)
+		if i%2 == 1 {
+			got.SetClip(image.Rect(3, 2, 11, 9))
+			want.SetClip(got.Clip)
+		}
+		got.Line(x0, y0, x1, y1, col)
+		lineWalk(want, x0, y0, x1, y1, col)
+		if !got.Equal(want) {
+			t.Fatalf("line %d,%d %d,%d clip %v differs from plain walk", x0, y0, x1, y1, got.Clip)
+		}
+	}
+
+	// Huge lines only walk visible steps
+	c := New(4, 3)
+	c.Line(-1_000_000_000, 1, 1_000_000_000, 1, col)
+	c.Line(2, -1_000_000_000, 2, 1_000_000_000, col)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			if on := c.At(x, y) == col; on != (y == 1 || x == 2) {
+				t.Errorf("pixel %d,%d drawn %v", x, y, on)
+			}
+		}
+	}
+}
+
+// picture builds canvas with col on every # of the rows
+func picture(col color.NRGBA, rows ...string) *Canvas {
+	c := New(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, ch := range row {
+			if ch == '#' {
+				c.Set(x, y, col)
+			}
+		}
+	}
+	return c
+}
+
+func expectCanvas(t *testing.T, name string, got, want *Canvas) {
+	t.Helper()
+	if !got.Equal(want) {
+		t.Errorf("%s got\n%v\nwant\n%v", name, got.Pix, want.Pix)
+	}
+}
+
+func TestCircle(t *testing.T) {
+	// Half transparent color shows pixels plotted twice
+	col := color.NRGBA{G: 255, A: 128}
+	c := New(7, 7)
+	c.Circle(3, 3, 2, col)
+	expectCanvas(t, "circle", c, picture(col,
+		".......",
+		"..###..",
+		".#...#.",
+		".#...#.",
+		".#...#.",
+		"..###..",
+		".......",
+	))
+
+	c = New(7, 7)
+	c.FillCircle(3, 3, 2, col)
+	expectCanvas(t, "filled circle", c, picture(col,
+		".......",
+		"...#...",
+		"..###..",
+		".#####.",
+		"..###..",
+		"...#...",
+		".......",
+	))
+
+	// Clipped to the canvas
+	c = New(4, 4)
+	c.FillCircle(0, 0, 2, col)
+	c.Circle(3, 3, 1, col)
+	expectCanvas(t, "clipped circles", c, picture(col,
+		"###.",
+		"##..",
+		"#..#",
+		"..#.",
+	))
+}
+
+func TestFillPolygon(t *testing.T) {
+	col := color.NRGBA{B: 255, A: 255}
+	c := New(5, 5)
+	c.FillPolygon([]Point{{0, 0}, {4, 0}, {0, 4}}, col)
+	expectCanvas(t, "triangle", c, picture(col,
+		"####.",
+		"###..",
+		"##...",
+		"#....",
+		".....",
+	))
+
+	// Even-odd rule leaves the inner square of the ring empty
+	c = New(6, 6)
+	c.FillPolygon([]Point{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}, {2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}, col)
+	expectCanvas(t, "ring", c, picture(col,
+		"######",
+		"######",
+		"##..##",
+		"##..##",
+		"######",
+		"######",
+	))
+
+	c = New(5, 5)
+	c.SetClip(image.Rect(1, 1, 3, 5))
+	c.FillPolygon([]Point{{0, 0}, {4, 0}, {0, 4}}, col)
+	expectCanvas(t, "clipped triangle", c, picture(col,
+		".....",
+		".##..",
+		".#...",
+		".....",
+		".....",
+	))
+}
+
+// lineAAWalk is the Wu loop over every column relying on plot to drop
+// pixels outside the clip
+func lineAAWalk(c *Canvas, x0, y0, x1, y1 float64, col color.NRGBA) {
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, x1, y0, y1 = x1, x0, y1, y0
+	}
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		c.plot(x, y, col, uint32(math.Round(coverage*255)))
+	}
+	gradient := 1.0
+	if x1 != x0 {
+		gradient = (y1 - y0) / (x1 - x0)
+	}
+	xend1 := math.Round(x0)
+	yend1 := y0 + gradient*(xend1-x0)
+	xend2 := math.Round(x1)
+	yend2 := y1 + gradient*(xend2-x1)
+	for x := xend1; x <= xend2; x++ {
+		intery, coverage := yend1+gradient*(x-xend1), 1.0
+		switch x {
+		case xend1:
+			coverage = 1 - frac(x0+0.5)
+		case xend2:
+			intery, coverage = yend2, frac(x1+0.5)
+		}
+		y := int(math.Floor(intery))
+		plot(int(x), y, (1-frac(intery))*coverage)
+		plot(int(x), y+1, frac(intery)*coverage)
+	}
+}
+
+func TestLineAA(t *testing.T) {
+	col := color.NRGBA{R: 255, G: 255, A: 255}
+	half := color.NRGBA{R: 255, G: 255, A: 128}
+	c := New(6, 3)
+	c.LineAA(1, 1, 4, 1, col)
+	want := picture(col,
+		"......",
+		"..##..",
+		"......",
+	)
+	// Ends are pixel centers so half of their pixel is covered
+	want.Set(1, 1, half)
+	want.Set(4, 1, half)
+	expectCanvas(t, "horizontal", c, want)
+
+	// Diagonal splits coverage between pixel pairs
+	c = New(4, 4)
+	c.LineAA(0, 0.5, 3, 2, col)
+	if a, b := c.At(1, 1).A, c.At(1, 2).A; a == 0 || b != 0 {
+		t.Errorf("column 1 alphas %d, %d", a, b)
+	}
+	if a, b := c.At(2, 1).A, c.At(2, 2).A; a == 0 || b == 0 || int(a)+int(b) < 250 {
+		t.Errorf("column 2 alphas %d, %d", a, b)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		x0, y0 := rng.Float64()*80-40, rng.Float64()*80-40
+		x1, y1 := rng.Float64()*80-40, rng.Float64()*80-40
+		got, want := New(16, 12), New(16, 12)
+		if i%2 == 1 {
+			got.SetClip(image.Rect(3, 2, 11, 9))
+			want.SetClip(got.Clip)
+		}
+		got.LineAA(x0, y0, x1, y1, col)
+		lineAAWalk(want, x0, y0, x1, y1, col)
+		if !got.Equal(want) {
+			t.Fatalf("line %v,%v %v,%v clip %v differs from plain walk", x0, y0, x1, y1, got.Clip)
+		}
+	}
+
+	// Huge lines only walk visible columns
+	c = New(4, 3)
+	c.LineAA(-1e9, 1, 1e9, 1, col)
+	c.LineAA(2, -1e30, 2, 1e30, col)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			if on := c.At(x, y) == col; on != (y == 1 || x == 2) {
+				t.Errorf("pixel %d,%d drawn %v", x, y, on)
+			}
+		}
+	}
+}
+
+func TestBlit(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 128}
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(1, 0, blue)
+	src.SetNRGBA(2, 1, red)
+
+	// Sub image starts at its own bounds, corner is cut by the canvas
+	c := New(3, 3)
+	c.Mode = Replace
+	c.Clear(color.NRGBA{G: 255, A: 255})
+	c.Blit(src.SubImage(image.Rect(1, 0, 3, 2)), image.Pt(-1, 1))
+	want := New(3, 3)
+	want.Clear(color.NRGBA{G: 255, A: 255})
+	want.Mode = Replace
+	want.Set(0, 1, color.NRGBA{})
+	want.Set(0, 2, red)
+	expectCanvas(t, "replace", c, want)
+
+	// Blend mixes the translucent pixel, transparent ones leave canvas alone
+	c = New(3, 2)
+	c.Clear(red)
+	c.Blit(src, image.Point{})
+	want = New(3, 2)
+	want.Clear(red)
+	want.Set(1, 0, blue)
+	expectCanvas(t, "blend", c, want)
+	if got := c.At(1, 0); got.R == 0 || got.B == 0 || got.A != 255 {
+		t.Errorf("blended pixel %v", got)
+	}
+}
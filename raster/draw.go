@@ -0,0 +1,254 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Line draws one pixel wide line with Bresenham algorithm, only the
+// steps inside the clip rectangle are walked
+func (c *Canvas) Line(x0, y0, x1, y1 int, col color.NRGBA) {
+	dx, dy := abs(x1-x0), abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	steps, minor := max(dx, dy), min(dx, dy)
+	// at returns pixel after k steps along the longer axis, the shorter
+	// one advances exactly as in the incremental Bresenham loop
+	at := func(k int) (int, int) {
+		m := 0
+		if steps > 0 {
+			m = (2*k*minor + steps) / (2 * steps)
+		}
+		if dx >= dy {
+			return x0 + sx*k, y0 + sy*m
+		}
+		return x0 + sx*m, y0 + sy*k
+	}
+	clip := c.Clip
+	// Both coordinates are monotonic in k so visible steps are contiguous
+	first := sort.Search(steps+1, func(k int) bool {
+		x, y := at(k)
+		return !before(x, sx, clip.Min.X, clip.Max.X) && !before(y, sy, clip.Min.Y, clip.Max.Y)
+	})
+	last := sort.Search(steps+1, func(k int) bool {
+		x, y := at(k)
+		return past(x, sx, clip.Min.X, clip.Max.X) || past(y, sy, clip.Min.Y, clip.Max.Y)
+	})
+	for k := first; k < last; k++ {
+		x, y := at(k)
+		c.Set(x, y, col)
+	}
+}
+
+// before reports whether v moving in direction s has not reached [lo, hi) yet
+func before(v, s, lo, hi int) bool {
+	if s < 0 {
+		return v >= hi
+	}
+	return v < lo
+}
+
+// past reports whether v moving in direction s has left [lo, hi)
+func past(v, s, lo, hi int) bool {
+	if s < 0 {
+		return v < lo
+	}
+	return v >= hi
+}
+
+// LineAA draws anti-aliased line with Xiaolin Wu algorithm, only the
+// columns inside the clip rectangle are walked
+func (c *Canvas) LineAA(x0, y0, x1, y1 float64, col color.NRGBA) {
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, x1, y0, y1 = x1, x0, y1, y0
+	}
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		c.plot(x, y, col, uint32(math.Round(coverage*255)))
+	}
+	// Visible columns along the walked axis
+	lo, hi := float64(c.Clip.Min.X), float64(c.Clip.Max.X)
+	if steep {
+		lo, hi = float64(c.Clip.Min.Y), float64(c.Clip.Max.Y)
+	}
+	dx := x1 - x0
+	gradient := 1.0
+	if dx != 0 {
+		gradient = (y1 - y0) / dx
+	}
+	// end plots the end point pair, far away ones are skipped before
+	// converting to int so huge coordinates don't overflow
+	end := func(xend, yend, xgap float64) {
+		if xend < lo || xend >= hi {
+			return
+		}
+		x, y := int(xend), int(math.Floor(yend))
+		plot(x, y, (1-frac(yend))*xgap)
+		plot(x, y+1, frac(yend)*xgap)
+	}
+
+	// First end point
+	xend1 := math.Round(x0)
+	yend1 := y0 + gradient*(xend1-x0)
+	end(xend1, yend1, 1-frac(x0+0.5))
+
+	// Second end point
+	xend2 := math.Round(x1)
+	end(xend2, y1+gradient*(xend2-x1), frac(x1+0.5))
+
+	for x := math.Max(xend1+1, lo); x < math.Min(xend2, hi); x++ {
+		intery := yend1 + gradient*(x-xend1)
+		y := int(math.Floor(intery))
+		plot(int(x), y, 1-frac(intery))
+		plot(int(x), y+1, frac(intery))
+	}
+}
+
+// Circle draws circle outline with midpoint algorithm
+func (c *Canvas) Circle(cx, cy, r int, col color.NRGBA) {
+	if r < 0 {
+		return
+	}
+	x, y, e := r, 0, 1-r
+	for x >= y {
+		// Octants meet on the diagonals, skip repeated pixels so blending stays even
+		points := [8]image.Point{
+			{cx + x, cy + y}, {cx + y, cy + x}, {cx - y, cy + x}, {cx - x, cy + y},
+			{cx - x, cy - y}, {cx - y, cy - x}, {cx + y, cy - x}, {cx + x, cy - y},
+		}
+	next:
+		for i, p := range points {
+			for _, q := range points[:i] {
+				if p == q {
+					continue next
+				}
+			}
+			c.Set(p.X, p.Y, col)
+		}
+		y++
+		if e < 0 {
+			e += 2*y + 1
+		} else {
+			x--
+			e += 2*(y-x) + 1
+		}
+	}
+}
+
+// FillCircle draws filled circle
+func (c *Canvas) FillCircle(cx, cy, r int, col color.NRGBA) {
+	for dy := -r; dy <= r; dy++ {
+		half := int(math.Sqrt(float64(r*r - dy*dy)))
+		c.hline(cx-half, cx+half, cy+dy, col)
+	}
+}
+
+// FillRect draws filled rectangle
+func (c *Canvas) FillRect(r image.Rectangle, col color.NRGBA) {
+	r = r.Intersect(c.Clip)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		c.hline(r.Min.X, r.Max.X-1, y, col)
+	}
+}
+
+// Rect draws rectangle outline
+func (c *Canvas) Rect(r image.Rectangle, col color.NRGBA) {
+	if r.Empty() {
+		return
+	}
+	c.hline(r.Min.X, r.Max.X-1, r.Min.Y, col)
+	if r.Dy() > 1 {
+		c.hline(r.Min.X, r.Max.X-1, r.Max.Y-1, col)
+	}
+	for y := r.Min.Y + 1; y < r.Max.Y-1; y++ {
+		c.Set(r.Min.X, y, col)
+		if r.Dx() > 1 {
+			c.Set(r.Max.X-1, y, col)
+		}
+	}
+}
+
+// Point is a polygon vertex
+type Point struct {
+	X, Y float64
+}
+
+// FillPolygon draws filled polygon with even-odd rule,
+// pixel is filled when its center is inside
+func (c *Canvas) FillPolygon(points []Point, col color.NRGBA) {
+	if len(points) < 3 {
+		return
+	}
+	top, bottom := points[0].Y, points[0].Y
+	for _, p := range points {
+		top, bottom = math.Min(top, p.Y), math.Max(bottom, p.Y)
+	}
+	first := max(int(math.Ceil(top-0.5)), c.Clip.Min.Y)
+	last := min(int(math.Floor(bottom-0.5)), c.Clip.Max.Y-1)
+	xs := make([]float64, 0, len(points))
+	for y := first; y <= last; y++ {
+		cy := float64(y) + 0.5
+		xs = xs[:0]
+		for i, a := range points {
+			b := points[(i+1)%len(points)]
+			if (a.Y <= cy) != (b.Y <= cy) {
+				xs = append(xs, a.X+(cy-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			c.hline(int(math.Ceil(xs[i]-0.5)), int(math.Floor(xs[i+1]-0.5)), y, col)
+		}
+	}
+}
+
+// hline draws horizontal span [x0, x1] clipped to the canvas
+func (c *Canvas) hline(x0, x1, y int, col color.NRGBA) {
+	if y < c.Clip.Min.Y || y >= c.Clip.Max.Y {
+		return
+	}
+	x0, x1 = max(x0, c.Clip.Min.X), min(x1, c.Clip.Max.X-1)
+	for x := x0; x <= x1; x++ {
+		c.Set(x, y, col)
+	}
+}
+
+// Blit draws image with its top left corner at dst using the blend mode
+func (c *Canvas) Blit(src image.Image, dst image.Point) {
+	b := src.Bounds()
+	area := image.Rectangle{Min: dst, Max: dst.Add(b.Size())}.Intersect(c.Clip)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			col := color.NRGBAModel.Convert(src.At(b.Min.X+x-dst.X, b.Min.Y+y-dst.Y)).(color.NRGBA)
+			c.Set(x, y, col)
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+func frac(v float64) float64 {
+	return v - math.Floor(v)
+}
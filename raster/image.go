@@ -0,0 +1,31 @@
+package raster
+
+import (
+	"image"
+	"image/draw"
+	"os"
+
+	// Decoders for Load
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Load decodes PNG, JPEG or GIF file
+func Load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// FromImage copies image into new canvas
+func FromImage(img image.Image) *Canvas {
+	b := img.Bounds()
+	c := New(b.Dx(), b.Dy())
+	draw.Draw(c.Image(), c.Bounds(), img, b.Min, draw.Src)
+	return c
+}
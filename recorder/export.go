@@ -0,0 +1,89 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// Export formats
+const (
+	FormatGIF = "gif"
+	FormatPNG = "png"
+)
+
+// ErrNoFrames is returned when there is nothing to export
+var ErrNoFrames = errors.New("recorder: no frames")
+
+// WriteGIF encodes looping animation, delays of the frames are in
+// hundredths of a second. Colors are reduced to the web-safe palette
+// with dithering.
+func WriteGIF(path string, frames []*image.NRGBA, delays []int) error {
+	if len(frames) == 0 {
+		return ErrNoFrames
+	}
+	if len(delays) != len(frames) {
+		return fmt.Errorf("recorder: %d delays for %d frames", len(delays), len(frames))
+	}
+	anim := &gif.GIF{Delay: delays}
+	for _, frame := range frames {
+		paletted := image.NewPaletted(frame.Bounds(), palette.WebSafe)
+		draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, image.Point{})
+		anim.Image = append(anim.Image, paletted)
+	}
+	return create(path, func(f *os.File) error {
+		return gif.EncodeAll(f, anim)
+	})
+}
+
+// WritePNGs saves frames as frame-0001.png and so on into dir
+func WritePNGs(dir string, frames []*image.NRGBA) error {
+	if len(frames) == 0 {
+		return ErrNoFrames
+	}
+	for i, frame := range frames {
+		path := filepath.Join(dir, fmt.Sprintf("frame-%04d.png", i+1))
+		err := create(path, func(f *os.File) error {
+			return png.Encode(f, frame)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export writes frames in the format, path without extension is
+// used as GIF file name or as directory of PNG frames
+func Export(format, path string, frames []*image.NRGBA, delays []int) (string, error) {
+	switch format {
+	case FormatGIF:
+		path += ".gif"
+		return path, WriteGIF(path, frames, delays)
+	case FormatPNG:
+		return path, WritePNGs(path, frames)
+	}
+	return "", fmt.Errorf("recorder: unknown format %q", format)
+}
+
+// create writes file with its directory
+func create(path string, write func(f *os.File) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
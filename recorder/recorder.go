@@ -0,0 +1,163 @@
+// Package recorder keeps captured frames and exports them as an
+// animated GIF or numbered PNG files
+package recorder
+
+import (
+	"image"
+)
+
+// MinDelay is the shortest GIF frame delay in hundredths of a second,
+// viewers slow down shorter ones
+const MinDelay = 2
+
+// Options of the recording
+type Options struct {
+	// Frames per second of the game
+	FPS int
+	// Frames dropped after each kept one, 1 halves frame rate,
+	// raised by New until kept frames are MinDelay apart
+	Skip int
+	// Frames are made this many times smaller
+	Scale int
+	// Most frames kept, older ones are dropped first
+	MaxFrames int
+}
+
+// Recorder collects frames in a ring buffer, it records either
+// a segment between Start and Stop or, when Replay is on, always
+// keeps the last MaxFrames frames
+type Recorder struct {
+	Options
+	// Keep recording without Start, for saving the last seconds
+	Replay    bool
+	recording bool
+	frames    []*image.NRGBA
+	// Index of the oldest frame and number of frames kept
+	first, count int
+	tick         int
+}
+
+// New creates recorder
+func New(o Options) *Recorder {
+	o.FPS = max(o.FPS, 1)
+	o.Skip = MinSkip(o.FPS, o.Skip)
+	o.Scale = max(o.Scale, 1)
+	o.MaxFrames = max(o.MaxFrames, 1)
+	return &Recorder{Options: o, frames: make([]*image.NRGBA, o.MaxFrames)}
+}
+
+// MinSkip returns skip raised so kept frames are at least MinDelay apart
+func MinSkip(fps, skip int) int {
+	skip = max(skip, 0)
+	for (skip+1)*100 < MinDelay*fps {
+		skip++
+	}
+	return skip
+}
+
+// FramesFor returns MaxFrames holding the seconds of gameplay
+func FramesFor(seconds float64, fps, skip int) int {
+	fps = max(fps, 1)
+	return max(int(seconds*float64(fps)/float64(MinSkip(fps, skip)+1)), 1)
+}
+
+// Start drops kept frames and starts recording a segment
+func (r *Recorder) Start() {
+	r.Reset()
+	r.recording = true
+}
+
+// Stop ends the segment and returns its frames
+func (r *Recorder) Stop() []*image.NRGBA {
+	r.recording = false
+	frames := r.Frames()
+	r.Reset()
+	return frames
+}
+
+// Recording reports whether a segment is being recorded
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// Wants is called once per game frame and reports whether the frame
+// should be captured and passed to Add, so skipped frames cost nothing
+func (r *Recorder) Wants() bool {
+	if !r.recording && !r.Replay {
+		return false
+	}
+	r.tick++
+	return (r.tick-1)%(r.Skip+1) == 0
+}
+
+// Add downscales frame and keeps it, the frame may be reused by caller
+func (r *Recorder) Add(frame *image.NRGBA) {
+	small := Downscale(frame, r.Scale)
+	if r.count < len(r.frames) {
+		r.frames[(r.first+r.count)%len(r.frames)] = small
+		r.count++
+		return
+	}
+	r.frames[r.first] = small
+	r.first = (r.first + 1) % len(r.frames)
+}
+
+// Len returns number of kept frames
+func (r *Recorder) Len() int {
+	return r.count
+}
+
+// Frames returns kept frames from the oldest
+func (r *Recorder) Frames() []*image.NRGBA {
+	frames := make([]*image.NRGBA, r.count)
+	for i := range frames {
+		frames[i] = r.frames[(r.first+i)%len(r.frames)]
+	}
+	return frames
+}
+
+// Reset drops kept frames
+func (r *Recorder) Reset() {
+	clear(r.frames)
+	r.first, r.count, r.tick = 0, 0, 0
+}
+
+// Delays returns delays of n kept frames in hundredths of a second,
+// fractions are carried to later frames so the total matches game time
+func (r *Recorder) Delays(n int) []int {
+	delays := make([]int, n)
+	at := func(i int) int {
+		return i * (r.Skip + 1) * 100 / r.FPS
+	}
+	for i := range delays {
+		delays[i] = at(i+1) - at(i)
+	}
+	return delays
+}
+
+// Downscale averages scale x scale blocks, scale 1 copies the image
+func Downscale(src *image.NRGBA, scale int) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx()/scale, b.Dy()/scale
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	area := uint32(scale * scale)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [4]uint32
+			for dy := 0; dy < scale; dy++ {
+				i := src.PixOffset(b.Min.X+x*scale, b.Min.Y+y*scale+dy)
+				for dx := 0; dx < scale; dx++ {
+					for k := 0; k < 4; k++ {
+						sum[k] += uint32(src.Pix[i+k])
+					}
+					i += 4
+				}
+			}
+			o := dst.PixOffset(x, y)
+			for k := 0; k < 4; k++ {
+				dst.Pix[o+k] = uint8(sum[k] / area)
+			}
+		}
+	}
+	return dst
+}
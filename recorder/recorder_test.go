@@ -0,0 +1,154 @@
+package recorder
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// frame returns 1x1 image whose red channel tells frames apart
+func frame(n int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: uint8(n), A: 255})
+	return img
+}
+
+func ids(frames []*image.NRGBA) []int {
+	var out []int
+	for _, f := range frames {
+		out = append(out, int(f.Pix[0]))
+	}
+	return out
+}
+
+func TestRing(t *testing.T) {
+	r := New(Options{FPS: 10, MaxFrames: 3})
+	r.Replay = true
+	for i := 1; i <= 2; i++ {
+		r.Add(frame(i))
+	}
+	if got := ids(r.Frames()); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("partly full ring %v", got)
+	}
+	for i := 3; i <= 7; i++ {
+		r.Add(frame(i))
+	}
+	if got := ids(r.Frames()); !reflect.DeepEqual(got, []int{5, 6, 7}) || r.Len() != 3 {
+		t.Errorf("wrapped ring %v, len %d", got, r.Len())
+	}
+
+	r.Start()
+	if r.Len() != 0 || !r.Recording() {
+		t.Fatalf("Start kept %d frames, recording %v", r.Len(), r.Recording())
+	}
+	r.Add(frame(8))
+	if got := ids(r.Stop()); !reflect.DeepEqual(got, []int{8}) || r.Len() != 0 {
+		t.Errorf("segment %v, left %d", got, r.Len())
+	}
+}
+
+func TestWants(t *testing.T) {
+	r := New(Options{FPS: 10, Skip: 2, MaxFrames: 10})
+	if r.Wants() {
+		t.Fatal("idle recorder wants frames")
+	}
+	r.Start()
+	var got []bool
+	for i := 0; i < 7; i++ {
+		got = append(got, r.Wants())
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wants %v\nwant %v", got, want)
+	}
+	r.Stop()
+	if r.Wants() {
+		t.Error("stopped recorder wants frames")
+	}
+}
+
+func TestDelays(t *testing.T) {
+	tests := []struct {
+		fps, skip, skipped int
+		delays             []int
+	}{
+		// 3.33 hundredths carried over, three frames take 10
+		{60, 1, 1, []int{3, 3, 4, 3, 3, 4}},
+		{30, 0, 0, []int{3, 3, 4, 3, 3, 4}},
+		{10, 0, 0, []int{10, 10, 10, 10, 10, 10}},
+		// Too fast for GIF, skip is raised to keep 2 hundredths
+		{60, 0, 1, []int{3, 3, 4, 3, 3, 4}},
+		{144, 0, 2, []int{2, 2, 2, 2, 2, 2}},
+	}
+	for _, tt := range tests {
+		r := New(Options{FPS: tt.fps, Skip: tt.skip})
+		if r.Skip != tt.skipped {
+			t.Errorf("%d fps skip %d raised to %d, want %d", tt.fps, tt.skip, r.Skip, tt.skipped)
+		}
+		if got := r.Delays(6); !reflect.DeepEqual(got, tt.delays) {
+			t.Errorf("%d fps skip %d delays %v want %v", tt.fps, tt.skip, got, tt.delays)
+		}
+	}
+	if n := FramesFor(2, 60, 0); n != 60 {
+		t.Errorf("2 seconds at 60 fps keep %d frames, want 60 after skip is raised", n)
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 5, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 10), G: uint8(y * 10), B: 7, A: 255})
+		}
+	}
+	small := Downscale(src, 2)
+	if small.Bounds() != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("bounds %v", small.Bounds())
+	}
+	// Blocks average 0,10 and 20,30 across rows 0 and 10
+	if c := small.NRGBAAt(0, 0); c != (color.NRGBA{5, 5, 7, 255}) {
+		t.Errorf("first block %v", c)
+	}
+	if c := small.NRGBAAt(1, 0); c != (color.NRGBA{25, 5, 7, 255}) {
+		t.Errorf("second block %v", c)
+	}
+	if same := Downscale(src, 1); !reflect.DeepEqual(same.Pix, src.Pix) || &same.Pix[0] == &src.Pix[0] {
+		t.Error("scale 1 is not a copy")
+	}
+
+	// Sub image offsets are honoured
+	sub := src.SubImage(image.Rect(2, 1, 4, 3)).(*image.NRGBA)
+	if c := Downscale(sub, 2).NRGBAAt(0, 0); c != (color.NRGBA{25, 15, 7, 255}) {
+		t.Errorf("sub image block %v", c)
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.gif")
+	frames := []*image.NRGBA{frame(0), frame(255)}
+	if err := WriteGIF(path, frames, []int{3}); err == nil {
+		t.Error("delay count mismatch accepted")
+	}
+	if err := WriteGIF(path, frames, []int{3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(anim.Delay, []int{3, 4}) {
+		t.Errorf("delays %v", anim.Delay)
+	}
+	if err := WriteGIF(path, nil, nil); err != ErrNoFrames {
+		t.Errorf("empty recording gave %v", err)
+	}
+}
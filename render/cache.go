@@ -0,0 +1,58 @@
+package render
+
+import "sync"
+
+// Cache shares textures loaded from the same file, every load takes
+// a reference and the texture is freed with its last reference
+type Cache struct {
+	mu    sync.Mutex
+	files map[string]*cached
+}
+
+type cached struct {
+	texture Texture
+	refs    int
+}
+
+// Load returns texture of the file, load is called only when the file
+// has no live texture yet
+func (c *Cache) Load(file string, load func() (Texture, error)) (Texture, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.files[file]; ok {
+		e.refs++
+		return e.texture, nil
+	}
+	t, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if c.files == nil {
+		c.files = make(map[string]*cached)
+	}
+	c.files[file] = &cached{texture: t, refs: 1}
+	return t, nil
+}
+
+// Release drops one reference of the file and reports whether it was
+// the last one, so the texture should be freed now
+func (c *Cache) Release(file string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.files[file]
+	if !ok {
+		return true
+	}
+	if e.refs--; e.refs > 0 {
+		return false
+	}
+	delete(c.files, file)
+	return true
+}
+
+// Len returns number of files with a live texture
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.files)
+}
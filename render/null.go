@@ -0,0 +1,38 @@
+package render
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// NullTexture is a texture without pixels
+type NullTexture struct {
+	File string
+	W, H int32
+	// Cache of the recorder which loaded the texture
+	cache *Cache
+}
+
+func (t *NullTexture) Size() (int32, int32) {
+	return t.W, t.H
+}
+
+func (t *NullTexture) Free() {
+	if t.cache != nil {
+		t.cache.Release(t.File)
+	}
+}
+
+// Null draws nothing, its textures have TextureW x TextureH size
+type Null struct {
+	TextureW, TextureH int32
+}
+
+func (n Null) LoadTexture(file string) (Texture, error) {
+	return &NullTexture{File: file, W: n.TextureW, H: n.TextureH}, nil
+}
+
+func (Null) SetColor(sdl.Color)                                   {}
+func (Null) DrawSprite(Texture, *sdl.Rect, *sdl.Rect)             {}
+func (Null) DrawSpriteEx(Texture, *sdl.Rect, *sdl.Rect, *Options) {}
+func (Null) DrawLine(int32, int32, int32, int32)                  {}
+func (Null) DrawRect(*sdl.Rect)                                   {}
+func (Null) FillRect(*sdl.Rect)                                   {}
+func (Null) Present()                                             {}
@@ -0,0 +1,462 @@
+// Package opengl is an OpenGL 3.3 core backend of the render API,
+// it batches textured quads so most frames take a few draw calls.
+package opengl
+
+import (
+	"image"
+	"math"
+	"sdl_learn/render"
+	"sync"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Required context version
+const (
+	MajorVersion = 3
+	MinorVersion = 3
+)
+
+// Vertex is x, y, u, v, r, g, b, a, flash
+const (
+	vertexFloats = 9
+	quadVertices = 4
+	quadIndices  = 6
+	// Quads in one draw call
+	MaxQuads = 4096
+)
+
+// Stats counts work done by the last frame
+type Stats struct {
+	DrawCalls, Quads, TextureSwitches int
+}
+
+// batch is a run of quads sharing a texture
+type batch struct {
+	texture      *Texture
+	first, quads int
+}
+
+// Device draws with OpenGL into the window, coordinates are
+// logical and scaled to the window keeping aspect ratio.
+// Game objects draw from their goroutines while GL works only
+// on the thread owning the context, so draw calls are collected
+// and the GL work is done by Present on the main thread.
+type Device struct {
+	win     *sdl.Window
+	context sdl.GLContext
+	program uint32
+	vao     uint32
+	vbo     uint32
+	ebo     uint32
+	// Projection uniform
+	projection int32
+	// White texture used for shapes
+	white *Texture
+
+	mu sync.Mutex
+	// Quads of the frame waiting for Present
+	vertices []float32
+	batches  []batch
+	color    [4]float32
+	// Textures freed from other goroutines and count of live ones
+	freed    []uint32
+	textures int
+	// Textures loaded from files by file name
+	cache render.Cache
+	// Post effects with two targets they take turns reading and writing
+	post    []effect
+	targets [2]target
+	lut     *Texture
+
+	// Logical size
+	Width, Height int32
+	// Scale only by whole numbers
+	IntegerScale bool
+	// Stats of the frame since Clear
+	Stats Stats
+	// Frame was cleared and post effects were not applied yet
+	started bool
+}
+
+// SetAttributes requests core context, call it before window is created
+// with sdl.WINDOW_OPENGL flag
+func SetAttributes() error {
+	for _, a := range []struct {
+		attr  sdl.GLattr
+		value int
+	}{
+		{sdl.GL_CONTEXT_PROFILE_MASK, sdl.GL_CONTEXT_PROFILE_CORE},
+		{sdl.GL_CONTEXT_MAJOR_VERSION, MajorVersion},
+		{sdl.GL_CONTEXT_MINOR_VERSION, MinorVersion},
+		{sdl.GL_DOUBLEBUFFER, 1},
+	} {
+		if err := sdl.GLSetAttribute(a.attr, a.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// New creates context for the window and logical size width x height
+func New(win *sdl.Window, width, height int32, vsync bool) (*Device, error) {
+	context, err := win.GLCreateContext()
+	if err != nil {
+		return nil, err
+	}
+	if err := gl.Init(); err != nil {
+		sdl.GLDeleteContext(context)
+		return nil, err
+	}
+	if vsync {
+		sdl.GLSetSwapInterval(1)
+	}
+
+	d := &Device{
+		win:      win,
+		context:  context,
+		vertices: make([]float32, 0, MaxQuads*quadVertices*vertexFloats),
+		color:    [4]float32{1, 1, 1, 1},
+		Width:    width,
+		Height:   height,
+	}
+	d.program, err = compile(vertexShader, fragmentShader)
+	if err != nil {
+		sdl.GLDeleteContext(context)
+		return nil, err
+	}
+	gl.UseProgram(d.program)
+	d.projection = uniform(d.program, "projection")
+	gl.Uniform1i(uniform(d.program, "sprite"), 0)
+	d.setupBuffers()
+
+	d.white = d.NewTexture(1, 1)
+	white := [4]byte{255, 255, 255, 255}
+	d.white.Update(gl.Ptr(&white[0]), 4)
+
+	gl.Enable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.ActiveTexture(gl.TEXTURE0)
+	return d, nil
+}
+
+// setupBuffers creates vertex buffer and index buffer shared by all quads
+func (d *Device) setupBuffers() {
+	gl.GenVertexArrays(1, &d.vao)
+	gl.BindVertexArray(d.vao)
+
+	gl.GenBuffers(1, &d.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, d.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, MaxQuads*quadVertices*vertexFloats*4, nil, gl.DYNAMIC_DRAW)
+
+	indices := make([]uint32, MaxQuads*quadIndices)
+	for i := 0; i < MaxQuads; i++ {
+		v := uint32(i * quadVertices)
+		copy(indices[i*quadIndices:], []uint32{v, v + 1, v + 2, v + 2, v + 3, v})
+	}
+	gl.GenBuffers(1, &d.ebo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, d.ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+
+	stride := int32(vertexFloats * 4)
+	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, stride, 0)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, stride, 2*4)
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(2, 4, gl.FLOAT, false, stride, 4*4)
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointerWithOffset(3, 1, gl.FLOAT, false, stride, 8*4)
+	gl.EnableVertexAttribArray(3)
+}
+
+// Destroy releases GL objects and the context
+func (d *Device) Destroy() {
+	d.white.Free()
+	d.freePost()
+	d.deleteFreed()
+	gl.DeleteBuffers(1, &d.vbo)
+	gl.DeleteBuffers(1, &d.ebo)
+	gl.DeleteVertexArrays(1, &d.vao)
+	gl.DeleteProgram(d.program)
+	sdl.GLDeleteContext(d.context)
+}
+
+// bindWindow makes the window viewport the target, bars are cleared
+func (d *Device) bindWindow() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	w, h := d.win.GLGetDrawableSize()
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.Viewport(0, 0, w, h)
+	gl.ClearColor(0, 0, 0, 1)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	// GL counts rows from the bottom
+	v := d.Viewport()
+	y := h - v.Y - v.H
+	gl.Viewport(v.X, y, v.W, v.H)
+	gl.Scissor(v.X, y, v.W, v.H)
+	gl.Enable(gl.SCISSOR_TEST)
+}
+
+// Viewport returns part of the window used by the logical size
+func (d *Device) Viewport() sdl.Rect {
+	w, h := d.win.GLGetDrawableSize()
+	scale := math.Min(float64(w)/float64(d.Width), float64(h)/float64(d.Height))
+	if d.IntegerScale && scale >= 1 {
+		scale = math.Floor(scale)
+	}
+	vw, vh := int32(float64(d.Width)*scale), int32(float64(d.Height)*scale)
+	return sdl.Rect{X: (w - vw) / 2, Y: (h - vh) / 2, W: vw, H: vh}
+}
+
+// Clear starts the frame, bars around the viewport are black,
+// with post effects the frame is drawn into a texture first
+func (d *Device) Clear(c sdl.Color) {
+	d.Stats = Stats{}
+	d.started = true
+	if len(d.post) > 0 {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, d.targets[0].fbo)
+		gl.Disable(gl.SCISSOR_TEST)
+		gl.Viewport(0, 0, d.Width, d.Height)
+	} else {
+		d.bindWindow()
+	}
+	gl.ClearColor(float32(c.R)/255, float32(c.G)/255, float32(c.B)/255, float32(c.A)/255)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	// Logical pixels with y going down
+	sx, sy := 2/float32(d.Width), -2/float32(d.Height)
+	projection := [16]float32{
+		sx, 0, 0, 0,
+		0, sy, 0, 0,
+		0, 0, -1, 0,
+		-1, 1, 0, 1,
+	}
+	gl.UseProgram(d.program)
+	gl.UniformMatrix4fv(d.projection, 1, false, &projection[0])
+}
+
+func (d *Device) SetColor(c sdl.Color) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.color = [4]float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255}
+}
+
+// DrawSprite draws only textures of this device
+func (d *Device) DrawSprite(t render.Texture, src, dst *sdl.Rect) {
+	texture, ok := t.(*Texture)
+	if !ok {
+		return
+	}
+	s := sdl.Rect{W: texture.W, H: texture.H}
+	if src != nil {
+		s = *src
+	}
+	r := sdl.Rect{W: d.Width, H: d.Height}
+	if dst != nil {
+		r = *dst
+	}
+	u0, v0 := float32(s.X)/float32(texture.W), float32(s.Y)/float32(texture.H)
+	u1, v1 := float32(s.X+s.W)/float32(texture.W), float32(s.Y+s.H)/float32(texture.H)
+	x0, y0 := float32(r.X), float32(r.Y)
+	x1, y1 := float32(r.X+r.W), float32(r.Y+r.H)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.quad(texture, [4]float32{1, 1, 1, 1}, 0,
+		x0, y0, u0, v0,
+		x1, y0, u1, v0,
+		x1, y1, u1, v1,
+		x0, y1, u0, v1)
+}
+
+// DrawSpriteEx rotates corners of the quad around the pivot,
+// flips swap texture coordinates
+func (d *Device) DrawSpriteEx(t render.Texture, src, dst *sdl.Rect, o *render.Options) {
+	texture, ok := t.(*Texture)
+	if !ok {
+		return
+	}
+	if o.Plain() {
+		d.DrawSprite(t, src, dst)
+		return
+	}
+	s := sdl.Rect{W: texture.W, H: texture.H}
+	if src != nil {
+		s = *src
+	}
+	r := sdl.Rect{W: d.Width, H: d.Height}
+	if dst != nil {
+		r = *dst
+	}
+	u0, v0 := float32(s.X)/float32(texture.W), float32(s.Y)/float32(texture.H)
+	u1, v1 := float32(s.X+s.W)/float32(texture.W), float32(s.Y+s.H)/float32(texture.H)
+	if o.Flip&render.FlipHorizontal != 0 {
+		u0, u1 = u1, u0
+	}
+	if o.Flip&render.FlipVertical != 0 {
+		v0, v1 = v1, v0
+	}
+
+	pivot := o.Center(r)
+	px, py := float32(r.X+pivot.X), float32(r.Y+pivot.Y)
+	sin, cos := math.Sincos(o.Angle * math.Pi / 180)
+	sn, cs := float32(sin), float32(cos)
+	// Corner relative to the pivot turned clockwise, y goes down
+	corner := func(x, y int32) (float32, float32) {
+		dx, dy := float32(x)-px, float32(y)-py
+		return px + dx*cs - dy*sn, py + dx*sn + dy*cs
+	}
+	x0, y0 := corner(r.X, r.Y)
+	x1, y1 := corner(r.X+r.W, r.Y)
+	x2, y2 := corner(r.X+r.W, r.Y+r.H)
+	x3, y3 := corner(r.X, r.Y+r.H)
+
+	mod := o.Mod()
+	c := [4]float32{float32(mod.R) / 255, float32(mod.G) / 255, float32(mod.B) / 255, float32(mod.A) / 255}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.quad(texture, c, min(max(o.Flash, 0), 1),
+		x0, y0, u0, v0,
+		x1, y1, u1, v0,
+		x2, y2, u1, v1,
+		x3, y3, u0, v1)
+}
+
+// DrawLine draws one pixel wide line including both ends
+func (d *Device) DrawLine(x1, y1, x2, y2 int32) {
+	ax, ay := float32(x1)+0.5, float32(y1)+0.5
+	bx, by := float32(x2)+0.5, float32(y2)+0.5
+	dx, dy := bx-ax, by-ay
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		d.FillRect(&sdl.Rect{X: x1, Y: y1, W: 1, H: 1})
+		return
+	}
+	// Half pixel along and across the line
+	dx, dy = dx/length/2, dy/length/2
+	nx, ny := -dy, dx
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.quad(d.white, d.color, 0,
+		ax-dx+nx, ay-dy+ny, 0, 0,
+		bx+dx+nx, by+dy+ny, 1, 0,
+		bx+dx-nx, by+dy-ny, 1, 1,
+		ax-dx-nx, ay-dy-ny, 0, 1)
+}
+
+func (d *Device) DrawRect(r *sdl.Rect) {
+	if r == nil {
+		r = &sdl.Rect{W: d.Width, H: d.Height}
+	}
+	if r.W <= 0 || r.H <= 0 {
+		return
+	}
+	d.FillRect(&sdl.Rect{X: r.X, Y: r.Y, W: r.W, H: 1})
+	d.FillRect(&sdl.Rect{X: r.X, Y: r.Y + r.H - 1, W: r.W, H: 1})
+	d.FillRect(&sdl.Rect{X: r.X, Y: r.Y + 1, W: 1, H: r.H - 2})
+	d.FillRect(&sdl.Rect{X: r.X + r.W - 1, Y: r.Y + 1, W: 1, H: r.H - 2})
+}
+
+func (d *Device) FillRect(r *sdl.Rect) {
+	if r == nil {
+		r = &sdl.Rect{W: d.Width, H: d.Height}
+	}
+	if r.W <= 0 || r.H <= 0 {
+		return
+	}
+	x0, y0 := float32(r.X), float32(r.Y)
+	x1, y1 := float32(r.X+r.W), float32(r.Y+r.H)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.quad(d.white, d.color, 0,
+		x0, y0, 0, 0,
+		x1, y0, 1, 0,
+		x1, y1, 1, 1,
+		x0, y1, 0, 1)
+}
+
+// quad adds corners to the frame, v holds x, y, u, v of each corner,
+// lock must be held
+func (d *Device) quad(t *Texture, c [4]float32, flash float32, v ...float32) {
+	n := len(d.batches)
+	if n == 0 || d.batches[n-1].texture != t || d.batches[n-1].quads == MaxQuads {
+		d.batches = append(d.batches, batch{texture: t, first: len(d.vertices) / (quadVertices * vertexFloats)})
+		n++
+	}
+	for i := 0; i < len(v); i += 4 {
+		d.vertices = append(d.vertices, v[i], v[i+1], v[i+2], v[i+3], c[0], c[1], c[2], c[3], flash)
+	}
+	d.batches[n-1].quads++
+}
+
+// Flush draws collected quads, one draw call per batch, and applies
+// post effects, the frame can be read with ReadPixels before Present
+func (d *Device) Flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	gl.BindVertexArray(d.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, d.vbo)
+	stats := Stats{Quads: len(d.vertices) / (quadVertices * vertexFloats)}
+	var last *Texture
+	for _, b := range d.batches {
+		if b.texture != last {
+			stats.TextureSwitches++
+			last = b.texture
+		}
+		floats := quadVertices * vertexFloats
+		gl.BufferSubData(gl.ARRAY_BUFFER, 0, b.quads*floats*4, gl.Ptr(d.vertices[b.first*floats:]))
+		if b.texture.Premultiplied {
+			gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+		} else {
+			gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+		}
+		gl.BindTexture(gl.TEXTURE_2D, b.texture.ID)
+		gl.DrawElementsWithOffset(gl.TRIANGLES, int32(b.quads*quadIndices), gl.UNSIGNED_INT, 0)
+		stats.DrawCalls++
+	}
+	d.vertices = d.vertices[:0]
+	d.batches = d.batches[:0]
+	if len(d.post) > 0 && d.started {
+		stats.DrawCalls += d.applyPost()
+	}
+	d.started = false
+	d.deleteFreed()
+	d.Stats.DrawCalls += stats.DrawCalls
+	d.Stats.Quads += stats.Quads
+	d.Stats.TextureSwitches += stats.TextureSwitches
+}
+
+// Present flushes the frame and shows it
+func (d *Device) Present() {
+	d.Flush()
+	d.win.GLSwap()
+}
+
+// ReadPixels returns the viewport of the flushed frame
+func (d *Device) ReadPixels() *image.NRGBA {
+	_, h := d.win.GLGetDrawableSize()
+	v := d.Viewport()
+	img := image.NewNRGBA(image.Rect(0, 0, int(v.W), int(v.H)))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(v.X, h-v.Y-v.H, v.W, v.H, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	// GL rows go from the bottom
+	row := make([]byte, img.Stride)
+	for top, bottom := 0, int(v.H)-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*img.Stride : (top+1)*img.Stride]
+		b := img.Pix[bottom*img.Stride : (bottom+1)*img.Stride]
+		copy(row, a)
+		copy(a, b)
+		copy(b, row)
+	}
+	return img
+}
+
+// deleteFreed deletes textures freed since the last frame
+func (d *Device) deleteFreed() {
+	if len(d.freed) > 0 {
+		gl.DeleteTextures(int32(len(d.freed)), &d.freed[0])
+		d.freed = d.freed[:0]
+	}
+}
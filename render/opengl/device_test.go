@@ -0,0 +1,97 @@
+package opengl
+
+import (
+	"image/color"
+	"runtime"
+	"testing"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// newTestDevice opens hidden window with a software GL context, the test
+// is skipped where no GL context can be made, e.g. without a display,
+// run it headless with xvfb-run go test -tags static ./render/opengl
+func newTestDevice(t *testing.T, w, h int32) *Device {
+	t.Helper()
+	runtime.LockOSThread()
+	t.Cleanup(runtime.UnlockOSThread)
+	t.Setenv("LIBGL_ALWAYS_SOFTWARE", "1")
+	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+		t.Skipf("no video: %v", err)
+	}
+	t.Cleanup(sdl.Quit)
+	if err := SetAttributes(); err != nil {
+		t.Skipf("no GL attributes: %v", err)
+	}
+	win, err := sdl.CreateWindow("test", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, w, h, sdl.WINDOW_OPENGL|sdl.WINDOW_HIDDEN)
+	if err != nil {
+		t.Skipf("no GL window: %v", err)
+	}
+	t.Cleanup(func() { win.Destroy() })
+	d, err := New(win, w, h, false)
+	if err != nil {
+		t.Skipf("no GL %d.%d context: %v", MajorVersion, MinorVersion, err)
+	}
+	t.Cleanup(d.Destroy)
+	return d
+}
+
+func TestDraw(t *testing.T) {
+	d := newTestDevice(t, 64, 32)
+
+	// 2x2 texture stretched to 16x16 shows 8x8 blocks
+	texture := d.NewTexture(2, 2)
+	pixels := [16]byte{
+		255, 0, 0, 255, 0, 255, 0, 255,
+		0, 0, 255, 255, 255, 255, 255, 0,
+	}
+	texture.Update(gl.Ptr(&pixels[0]), 8)
+	defer texture.Free()
+
+	d.Clear(sdl.Color{A: 255})
+	d.SetColor(sdl.Color{R: 255, A: 255})
+	d.FillRect(&sdl.Rect{X: 0, Y: 0, W: 16, H: 16})
+	// Half transparent blue blends over the red corner
+	d.SetColor(sdl.Color{B: 255, A: 128})
+	d.FillRect(&sdl.Rect{X: 8, Y: 8, W: 16, H: 16})
+	d.SetColor(sdl.Color{R: 255, G: 255, B: 255, A: 255})
+	d.DrawSprite(texture, nil, &sdl.Rect{X: 32, Y: 8, W: 16, H: 16})
+	// ReadPixels reads the back buffer, so before Present swaps it
+	d.Flush()
+	img := d.ReadPixels()
+	d.Present()
+
+	if img.Rect.Dx() != 64 || img.Rect.Dy() != 32 {
+		t.Fatalf("read %v want 64x32", img.Rect)
+	}
+	if d.Stats.Quads != 3 || d.Stats.DrawCalls != 2 {
+		t.Errorf("stats %+v want 3 quads in 2 draw calls", d.Stats)
+	}
+	for _, tt := range []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{2, 2, color.NRGBA{255, 0, 0, 255}},
+		{12, 12, color.NRGBA{127, 0, 128, 255}},
+		{20, 20, color.NRGBA{0, 0, 128, 255}},
+		{30, 2, color.NRGBA{0, 0, 0, 255}},
+		{34, 10, color.NRGBA{255, 0, 0, 255}},
+		{44, 10, color.NRGBA{0, 255, 0, 255}},
+		{34, 20, color.NRGBA{0, 0, 255, 255}},
+		// Transparent texel leaves the background
+		{44, 20, color.NRGBA{0, 0, 0, 255}},
+		{63, 31, color.NRGBA{0, 0, 0, 255}},
+	} {
+		if got := img.NRGBAAt(tt.x, tt.y); !near(got, tt.want) {
+			t.Errorf("pixel %d,%d is %v want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+// near allows rounding differences of GL blending, alpha is not
+// compared as the window may have no alpha channel
+func near(a, b color.NRGBA) bool {
+	d := func(x, y uint8) bool { return max(x, y)-min(x, y) <= 2 }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B)
+}
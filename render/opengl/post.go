@@ -0,0 +1,234 @@
+package opengl
+
+import (
+	"fmt"
+	"sdl_learn/settings"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Full screen triangle made from vertex ids, uv covers the screen
+const postVertexShader = `#version 330 core
+out vec2 uv;
+void main() {
+	vec2 p = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+	uv = p;
+	gl_Position = vec4(p * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+// Uniforms shared by effect shaders
+const postHeader = `#version 330 core
+in vec2 uv;
+uniform sampler2D frame;
+uniform sampler2D lut;
+uniform vec2 size;
+out vec4 outColor;
+`
+
+// Effect shaders by settings name
+var postShaders = map[string]string{
+	// Barrel curvature, scanlines between logical rows and darker corners
+	settings.EffectCRT: `
+void main() {
+	vec2 p = uv * 2.0 - 1.0;
+	p *= 1.0 + 0.04 * dot(p.yx, p.yx);
+	p = p * 0.5 + 0.5;
+	if (p.x < 0.0 || p.x > 1.0 || p.y < 0.0 || p.y > 1.0) {
+		outColor = vec4(0.0, 0.0, 0.0, 1.0);
+		return;
+	}
+	vec3 c = texture(frame, p).rgb;
+	float line = sin(p.y * size.y * 3.14159265);
+	c *= mix(1.0, line * line, 0.35);
+	// Shadow mask, every third column favors one channel
+	int column = int(p.x * size.x) % 3;
+	vec3 mask = vec3(0.9);
+	mask[column] = 1.1;
+	outColor = vec4(c * mask, 1.0);
+}
+`,
+	// Bright parts blurred with a gaussian kernel and added back
+	settings.EffectBloom: `
+vec3 bright(vec2 p) {
+	vec3 c = texture(frame, p).rgb;
+	return c * smoothstep(0.55, 0.9, max(c.r, max(c.g, c.b)));
+}
+void main() {
+	vec3 sum = vec3(0.0);
+	float total = 0.0;
+	for (int x = -4; x <= 4; x++) {
+		for (int y = -4; y <= 4; y++) {
+			float w = exp(-float(x * x + y * y) / 8.0);
+			sum += bright(uv + vec2(x, y) * 2.0 / size) * w;
+			total += w;
+		}
+	}
+	outColor = vec4(texture(frame, uv).rgb + sum / total * 1.5, 1.0);
+}
+`,
+	settings.EffectVignette: `
+void main() {
+	float v = smoothstep(0.85, 0.35, length(uv - 0.5));
+	outColor = vec4(texture(frame, uv).rgb * mix(1.0, v, 0.6), 1.0);
+}
+`,
+	// Lookup in 16 slices of 16x16, blue picks the slice
+	settings.EffectGrade: `
+vec3 lookup(vec3 c, float slice) {
+	vec2 p = vec2((slice * 16.0 + c.r * 15.0 + 0.5) / 256.0, (c.g * 15.0 + 0.5) / 16.0);
+	return texture(lut, p).rgb;
+}
+void main() {
+	vec3 c = clamp(texture(frame, uv).rgb, 0.0, 1.0);
+	float b = c.b * 15.0;
+	float slice = floor(b);
+	vec3 graded = mix(lookup(c, slice), lookup(c, min(slice + 1.0, 15.0)), b - slice);
+	outColor = vec4(graded, 1.0);
+}
+`,
+}
+
+// LUT size, 16 levels per channel
+const (
+	lutWidth  = 256
+	lutHeight = 16
+)
+
+// effect is a compiled post-processing pass
+type effect struct {
+	name    string
+	program uint32
+	size    int32
+}
+
+// target is a framebuffer rendering into a texture
+type target struct {
+	fbo     uint32
+	texture *Texture
+}
+
+// SetPostEffects compiles effects applied by Present in the given order,
+// lut is a color grading image file used by the grade effect, identity when empty.
+// It must be called on the main thread, empty names turn effects off.
+func (d *Device) SetPostEffects(names []string, lut string) error {
+	d.freePost()
+	for _, name := range names {
+		source, ok := postShaders[name]
+		if !ok {
+			return fmt.Errorf("opengl: unknown effect %q", name)
+		}
+		program, err := compile(postVertexShader, postHeader+source)
+		if err != nil {
+			d.freePost()
+			return fmt.Errorf("opengl: effect %s: %w", name, err)
+		}
+		gl.UseProgram(program)
+		gl.Uniform1i(uniform(program, "frame"), 0)
+		gl.Uniform1i(uniform(program, "lut"), 1)
+		d.post = append(d.post, effect{name: name, program: program, size: uniform(program, "size")})
+	}
+	gl.UseProgram(d.program)
+	if len(d.post) == 0 {
+		return nil
+	}
+
+	for i := range d.targets {
+		t := &d.targets[i]
+		t.texture = d.NewTexture(d.Width, d.Height)
+		gl.BindTexture(gl.TEXTURE_2D, t.texture.ID)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+		gl.GenFramebuffers(1, &t.fbo)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.texture.ID, 0)
+		if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			d.freePost()
+			return fmt.Errorf("opengl: framebuffer status %#x", status)
+		}
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if err := d.loadLUT(lut); err != nil {
+		d.freePost()
+		return err
+	}
+	return nil
+}
+
+// loadLUT loads grading image or creates identity one
+func (d *Device) loadLUT(file string) error {
+	if file != "" {
+		t, err := d.LoadTexture(file)
+		if err != nil {
+			return err
+		}
+		if w, h := t.Size(); w != lutWidth || h != lutHeight {
+			t.Free()
+			return fmt.Errorf("opengl: lut %s is %dx%d, want %dx%d", file, w, h, lutWidth, lutHeight)
+		}
+		d.lut = t.(*Texture)
+	} else {
+		pixels := make([]byte, lutWidth*lutHeight*4)
+		for y := 0; y < lutHeight; y++ {
+			for x := 0; x < lutWidth; x++ {
+				i := (y*lutWidth + x) * 4
+				pixels[i] = byte((x % 16) * 255 / 15)
+				pixels[i+1] = byte(y * 255 / 15)
+				pixels[i+2] = byte((x / 16) * 255 / 15)
+				pixels[i+3] = 255
+			}
+		}
+		d.lut = d.NewTexture(lutWidth, lutHeight)
+		d.lut.Update(gl.Ptr(pixels), lutWidth*4)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, d.lut.ID)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	return nil
+}
+
+// freePost deletes effects and their framebuffers
+func (d *Device) freePost() {
+	for _, e := range d.post {
+		gl.DeleteProgram(e.program)
+	}
+	d.post = nil
+	for i := range d.targets {
+		t := &d.targets[i]
+		if t.texture != nil {
+			gl.DeleteFramebuffers(1, &t.fbo)
+			t.texture.Free()
+			t.texture, t.fbo = nil, 0
+		}
+	}
+	if d.lut != nil {
+		d.lut.Free()
+		d.lut = nil
+	}
+}
+
+// applyPost runs effects over the frame drawn into the first target,
+// the last one draws into the window viewport, it returns pass count
+func (d *Device) applyPost() int {
+	gl.Disable(gl.BLEND)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, d.lut.ID)
+	gl.ActiveTexture(gl.TEXTURE0)
+	src := 0
+	for i, e := range d.post {
+		if i == len(d.post)-1 {
+			d.bindWindow()
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, d.targets[1-src].fbo)
+		}
+		gl.UseProgram(e.program)
+		gl.Uniform2f(e.size, float32(d.Width), float32(d.Height))
+		gl.BindTexture(gl.TEXTURE_2D, d.targets[src].texture.ID)
+		gl.DrawArrays(gl.TRIANGLES, 0, 3)
+		src = 1 - src
+	}
+	gl.UseProgram(d.program)
+	gl.Enable(gl.BLEND)
+	return len(d.post)
+}
@@ -0,0 +1,94 @@
+package opengl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Sprite shader, color multiplies the texture so shapes use a white one,
+// flash mixes white into the color keeping its alpha
+const (
+	vertexShader = `#version 330 core
+layout(location = 0) in vec2 position;
+layout(location = 1) in vec2 uv;
+layout(location = 2) in vec4 color;
+layout(location = 3) in float flash;
+uniform mat4 projection;
+out vec2 fragUV;
+out vec4 fragColor;
+out float fragFlash;
+void main() {
+	fragUV = uv;
+	fragColor = color;
+	fragFlash = flash;
+	gl_Position = projection * vec4(position, 0.0, 1.0);
+}
+`
+	fragmentShader = `#version 330 core
+in vec2 fragUV;
+in vec4 fragColor;
+in float fragFlash;
+uniform sampler2D sprite;
+out vec4 outColor;
+void main() {
+	outColor = texture(sprite, fragUV) * fragColor;
+	outColor.rgb = mix(outColor.rgb, vec3(1.0), fragFlash);
+}
+`
+)
+
+// compile builds shader program from sources
+func compile(vertex, fragment string) (uint32, error) {
+	vs, err := compileShader(vertex, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(vs)
+	fs, err := compileShader(fragment, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, err
+	}
+	defer gl.DeleteShader(fs)
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vs)
+	gl.AttachShader(program, fs)
+	gl.LinkProgram(program)
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var length int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &length)
+		log := strings.Repeat("\x00", int(length+1))
+		gl.GetProgramInfoLog(program, length, nil, gl.Str(log))
+		gl.DeleteProgram(program)
+		return 0, fmt.Errorf("opengl: link program: %s", strings.TrimRight(log, "\x00"))
+	}
+	return program, nil
+}
+
+func compileShader(source string, kind uint32) (uint32, error) {
+	shader := gl.CreateShader(kind)
+	csources, free := gl.Strs(source + "\x00")
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var length int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &length)
+		log := strings.Repeat("\x00", int(length+1))
+		gl.GetShaderInfoLog(shader, length, nil, gl.Str(log))
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("opengl: compile shader: %s", strings.TrimRight(log, "\x00"))
+	}
+	return shader, nil
+}
+
+// uniform returns location of the program uniform
+func uniform(program uint32, name string) int32 {
+	return gl.GetUniformLocation(program, gl.Str(name+"\x00"))
+}
@@ -0,0 +1,111 @@
+package opengl
+
+import (
+	"sdl_learn/render"
+	"unsafe"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Texture is an RGBA texture of the OpenGL device
+type Texture struct {
+	device *Device
+	ID     uint32
+	W, H   int32
+	// Premultiplied textures have color already multiplied by alpha,
+	// like ones drawn by SDL onto a transparent target
+	Premultiplied bool
+	// Image file of textures shared by LoadTexture
+	file string
+}
+
+// NewTexture creates empty texture, pixels are set with Update,
+// it must be called on the main thread
+func (d *Device) NewTexture(w, h int32) *Texture {
+	t := &Texture{device: d, W: w, H: h}
+	gl.GenTextures(1, &t.ID)
+	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, w, h, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	d.mu.Lock()
+	d.textures++
+	d.mu.Unlock()
+	return t
+}
+
+// Update replaces texture pixels, rows are pitch bytes long,
+// it must be called on the main thread
+func (t *Texture) Update(pixels unsafe.Pointer, pitch int32) {
+	gl.BindTexture(gl.TEXTURE_2D, t.ID)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, pitch/4)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, t.W, t.H, gl.RGBA, gl.UNSIGNED_BYTE, pixels)
+	gl.PixelStorei(gl.UNPACK_ROW_LENGTH, 0)
+}
+
+// UpdateSurface replaces texture pixels with the surface of the same size
+func (t *Texture) UpdateSurface(s *sdl.Surface) error {
+	if s.Format.Format != sdl.PIXELFORMAT_ABGR8888 {
+		converted, err := s.ConvertFormat(sdl.PIXELFORMAT_ABGR8888, 0)
+		if err != nil {
+			return err
+		}
+		defer converted.Free()
+		s = converted
+	}
+	s.Lock()
+	defer s.Unlock()
+	t.Update(s.Data(), s.Pitch)
+	return nil
+}
+
+func (t *Texture) Size() (int32, int32) {
+	return t.W, t.H
+}
+
+// Free deletes texture after the frame, so it is safe to call
+// from any goroutine, a loaded texture is deleted when every load
+// of its file is freed
+func (t *Texture) Free() {
+	if t.file != "" && !t.device.cache.Release(t.file) {
+		return
+	}
+	t.device.mu.Lock()
+	defer t.device.mu.Unlock()
+	t.device.freed = append(t.device.freed, t.ID)
+	t.device.textures--
+}
+
+// TextureCount returns number of textures not freed yet
+func (d *Device) TextureCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.textures
+}
+
+// LoadTexture loads image file into a texture, loads of the same file
+// share the texture, it must be called on the main thread
+func (d *Device) LoadTexture(file string) (render.Texture, error) {
+	return d.cache.Load(file, func() (render.Texture, error) {
+		return d.load(file)
+	})
+}
+
+func (d *Device) load(file string) (render.Texture, error) {
+	s, err := img.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Free()
+	t := d.NewTexture(s.W, s.H)
+	if err := t.UpdateSurface(s); err != nil {
+		t.Free()
+		return nil, err
+	}
+	t.file = file
+	return t, nil
+}
@@ -0,0 +1,46 @@
+package render
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// Flip mirrors sprite, flags can be combined
+type Flip uint8
+
+const (
+	FlipHorizontal Flip = 1 << iota
+	FlipVertical
+)
+
+// Options change how a sprite is drawn, zero value draws it as is
+type Options struct {
+	// Rotation in degrees clockwise around Pivot
+	Angle float64
+	// Rotation point relative to the dst corner, nil for the middle
+	Pivot *sdl.Point
+	Flip  Flip
+	// Color and alpha multiplied with the texture,
+	// zero value keeps the texture as is
+	Color sdl.Color
+	// How much the sprite is turned white, 0 to 1
+	Flash float32
+}
+
+// Plain reports whether options leave the sprite unchanged
+func (o *Options) Plain() bool {
+	return o == nil || (o.Angle == 0 && o.Flip == 0 && o.Color == (sdl.Color{}) && o.Flash <= 0)
+}
+
+// Mod returns color the texture is multiplied with
+func (o *Options) Mod() sdl.Color {
+	if o == nil || o.Color == (sdl.Color{}) {
+		return sdl.Color{R: 255, G: 255, B: 255, A: 255}
+	}
+	return o.Color
+}
+
+// Center returns rotation point relative to the dst corner
+func (o *Options) Center(dst sdl.Rect) sdl.Point {
+	if o == nil || o.Pivot == nil {
+		return sdl.Point{X: dst.W / 2, Y: dst.H / 2}
+	}
+	return *o.Pivot
+}
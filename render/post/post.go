@@ -0,0 +1,213 @@
+// Package post applies full screen effects to frames drawn with the
+// SDL renderer. SDL has no shaders, so only a subset is supported:
+// scanlines from the CRT effect, vignette and bloom approximated by
+// adding a blurry downscaled copy of the parts brighter than a
+// threshold. Curvature and color grading need the OpenGL backend,
+// bloom is skipped on renderers without subtract blending.
+package post
+
+import (
+	"image/color"
+	"math"
+	"sdl_learn/raster"
+	"sdl_learn/settings"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Bloom copy is this many times smaller than the frame, it is
+// halved step by step so every pixel averages the whole block
+const bloomDownscale = 8
+
+// Strength of the added bloom copy
+const bloomAlpha = 110
+
+// Channel level subtracted before the blur, darker parts do not glow
+const bloomThreshold = 150
+
+// Chain renders the frame into a texture and applies effects
+// in order when the frame is finished
+type Chain struct {
+	Width, Height int32
+	// Unsupported effects of the settings
+	Skipped []string
+	effects []string
+	frame   *sdl.Texture
+	// Thresholded copy of the frame and its halvings, the last one
+	// is added over the frame
+	bloom     []*sdl.Texture
+	threshold sdl.BlendMode
+	// Overlays of scanlines and vignette
+	overlays map[string]*sdl.Texture
+}
+
+// New creates textures for the effects, renderer must support
+// render targets, chain without supported effects does nothing
+func New(r *sdl.Renderer, width, height int32, effects []string) (*Chain, error) {
+	c := &Chain{Width: width, Height: height, overlays: make(map[string]*sdl.Texture)}
+	var err error
+	for _, name := range effects {
+		switch name {
+		case settings.EffectCRT:
+			c.overlays[name], err = overlay(r, int(width), int(height), scanline)
+		case settings.EffectVignette:
+			c.overlays[name], err = overlay(r, int(width), int(height), vignette)
+		case settings.EffectBloom:
+			// Subtracting the threshold needs a custom blend mode
+			c.threshold = sdl.ComposeCustomBlendMode(
+				sdl.BLENDFACTOR_ONE, sdl.BLENDFACTOR_ONE, sdl.BLENDOPERATION_REV_SUBTRACT,
+				sdl.BLENDFACTOR_ZERO, sdl.BLENDFACTOR_ONE, sdl.BLENDOPERATION_ADD)
+			if !supports(r, c.threshold) {
+				c.Skipped = append(c.Skipped, name)
+				continue
+			}
+			err = c.createBloom(r)
+		default:
+			c.Skipped = append(c.Skipped, name)
+			continue
+		}
+		if err != nil {
+			c.Free()
+			return nil, err
+		}
+		c.effects = append(c.effects, name)
+	}
+	if len(c.effects) == 0 {
+		return c, nil
+	}
+	c.frame, err = r.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_TARGET, width, height)
+	if err != nil {
+		c.Free()
+		return nil, err
+	}
+	return c, nil
+}
+
+// supports reports whether renderer can draw with the blend mode
+func supports(r *sdl.Renderer, mode sdl.BlendMode) bool {
+	var old sdl.BlendMode
+	r.GetDrawBlendMode(&old)
+	defer r.SetDrawBlendMode(old)
+	return r.SetDrawBlendMode(mode) == nil
+}
+
+// createBloom creates a full size copy and its halvings down to
+// bloomDownscale, linear scaling averages pixels of every halving
+func (c *Chain) createBloom(r *sdl.Renderer) error {
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "linear")
+	defer sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "nearest")
+	for scale := int32(1); scale <= bloomDownscale; scale *= 2 {
+		t, err := r.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_TARGET, c.Width/scale, c.Height/scale)
+		if err != nil {
+			return err
+		}
+		t.SetBlendMode(sdl.BLENDMODE_NONE)
+		c.bloom = append(c.bloom, t)
+	}
+	last := c.bloom[len(c.bloom)-1]
+	last.SetBlendMode(sdl.BLENDMODE_ADD)
+	last.SetAlphaMod(bloomAlpha)
+	return nil
+}
+
+// overlay creates texture blended over the frame, shade gives
+// color of each pixel
+func overlay(r *sdl.Renderer, w, h int, shade func(x, y, w, h int) color.NRGBA) (*sdl.Texture, error) {
+	c := raster.New(w, h)
+	c.Mode = raster.Replace
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c.Set(x, y, shade(x, y, w, h))
+		}
+	}
+	t, err := r.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STATIC, int32(w), int32(h))
+	if err != nil {
+		return nil, err
+	}
+	t.Update(nil, unsafe.Pointer(&c.Pix[0]), w*4)
+	t.SetBlendMode(sdl.BLENDMODE_BLEND)
+	return t, nil
+}
+
+// scanline darkens every second row
+func scanline(x, y, w, h int) color.NRGBA {
+	if y%2 == 1 {
+		return color.NRGBA{A: 90}
+	}
+	return color.NRGBA{}
+}
+
+// vignette darkens the corners
+func vignette(x, y, w, h int) color.NRGBA {
+	dx := float64(x)/float64(w) - 0.5
+	dy := float64(y)/float64(h) - 0.5
+	t := math.Min(math.Max((math.Hypot(dx, dy)-0.35)/0.5, 0), 1)
+	return color.NRGBA{A: uint8(t * t * (3 - 2*t) * 0.6 * 255)}
+}
+
+// Enabled reports whether chain has anything to apply
+func (c *Chain) Enabled() bool {
+	return len(c.effects) > 0
+}
+
+// Begin redirects drawing into the frame texture
+func (c *Chain) Begin(r *sdl.Renderer) {
+	if c.Enabled() {
+		r.SetRenderTarget(c.frame)
+	}
+}
+
+// End draws the frame to the window and applies effects
+func (c *Chain) End(r *sdl.Renderer) {
+	if !c.Enabled() {
+		return
+	}
+	r.SetRenderTarget(nil)
+	r.SetDrawColor(0, 0, 0, 255)
+	r.Clear()
+	full := sdl.Rect{W: c.Width, H: c.Height}
+	r.Copy(c.frame, nil, &full)
+	for _, name := range c.effects {
+		if name == settings.EffectBloom {
+			c.drawBloom(r, &full)
+			continue
+		}
+		r.Copy(c.overlays[name], nil, &full)
+	}
+}
+
+// drawBloom keeps the bright parts of the frame, halves them
+// and adds the result over the window
+func (c *Chain) drawBloom(r *sdl.Renderer, full *sdl.Rect) {
+	r.SetRenderTarget(c.bloom[0])
+	r.Clear()
+	r.Copy(c.frame, nil, nil)
+	var mode sdl.BlendMode
+	r.GetDrawBlendMode(&mode)
+	r.SetDrawBlendMode(c.threshold)
+	r.SetDrawColor(bloomThreshold, bloomThreshold, bloomThreshold, 0)
+	r.FillRect(nil)
+	r.SetDrawBlendMode(mode)
+	for i := 1; i < len(c.bloom); i++ {
+		r.SetRenderTarget(c.bloom[i])
+		r.Copy(c.bloom[i-1], nil, nil)
+	}
+	r.SetRenderTarget(nil)
+	r.Copy(c.bloom[len(c.bloom)-1], nil, full)
+}
+
+// Free resources
+func (c *Chain) Free() {
+	for _, t := range c.overlays {
+		t.Destroy()
+	}
+	c.overlays = nil
+	for _, t := range c.bloom {
+		t.Destroy()
+	}
+	c.bloom = nil
+	if c.frame != nil {
+		c.frame.Destroy()
+	}
+}
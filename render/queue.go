@@ -0,0 +1,150 @@
+package render
+
+import (
+	"sort"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Layer groups sprites, higher layers are drawn on top
+type Layer int
+
+const (
+	LayerBackground Layer = iota
+	LayerEnemies
+	LayerBullets
+	LayerPlayer
+	LayerEffects
+	LayerUI
+)
+
+// Sprite is a texture copy waiting in the queue
+type Sprite struct {
+	Texture Texture
+	// Part of the texture, nil for whole texture
+	Src *sdl.Rect
+	// Part of the screen where to draw
+	Dst   sdl.Rect
+	Layer Layer
+	// Order inside the layer, higher on top
+	Z int32
+	// Order inside the same Z, higher on top, sprites submitted from
+	// maps set it so the frame does not depend on iteration order
+	Seq uint64
+	// Rotation, flip, tint and flash, pivot is scaled with dst
+	Options Options
+	// Submit order
+	n int
+}
+
+// Stats counts work done by the last Flush
+type Stats struct {
+	Sprites, DrawCalls, TextureSwitches int
+}
+
+// Queue collects sprites during the frame and draws them in order
+type Queue struct {
+	sprites []Sprite
+	// Lowest sprite of every texture, used to group sprites
+	textures map[Texture]rank
+	// Stats of the last Flush
+	Stats Stats
+}
+
+// rank orders sprites of the same Z, by Seq then by submit order
+type rank struct {
+	seq uint64
+	n   int
+}
+
+func (r rank) less(other rank) bool {
+	if r.seq != other.seq {
+		return r.seq < other.seq
+	}
+	return r.n < other.n
+}
+
+// NewQueue creates empty queue
+func NewQueue() *Queue {
+	return &Queue{textures: make(map[Texture]rank)}
+}
+
+// Submit adds sprite to the frame
+func (q *Queue) Submit(s Sprite) {
+	if s.Texture == nil {
+		return
+	}
+	s.n = len(q.sprites)
+	r := rank{s.Seq, s.n}
+	if first, ok := q.textures[s.Texture]; !ok || r.less(first) {
+		q.textures[s.Texture] = r
+	}
+	q.sprites = append(q.sprites, s)
+}
+
+// Len returns number of sprites waiting
+func (q *Queue) Len() int {
+	return len(q.sprites)
+}
+
+// Sort orders sprites by layer, then Z, then texture so sprites
+// sharing a texture go one after another, then Seq and submit order;
+// textures go in order of their lowest sprite so the result does not
+// depend on the order of sprites with distinct Seq
+func (q *Queue) Sort() {
+	sort.Slice(q.sprites, func(i, j int) bool {
+		a, b := &q.sprites[i], &q.sprites[j]
+		if a.Layer != b.Layer {
+			return a.Layer < b.Layer
+		}
+		if a.Z != b.Z {
+			return a.Z < b.Z
+		}
+		if a.Texture != b.Texture {
+			return q.textures[a.Texture].less(q.textures[b.Texture])
+		}
+		return rank{a.Seq, a.n}.less(rank{b.Seq, b.n})
+	})
+}
+
+// Flush draws all sprites and empties the queue, sprites are
+// moved by the transform except for the UI layer, nil is Identity
+func (q *Queue) Flush(r Renderer, t Transform) {
+	if t == nil {
+		t = Identity{}
+	}
+	q.Sort()
+	q.Stats = Stats{Sprites: len(q.sprites)}
+	var last Texture
+	for i := range q.sprites {
+		s := &q.sprites[i]
+		if s.Texture != last {
+			q.Stats.TextureSwitches++
+			last = s.Texture
+		}
+		dst := s.Dst
+		if s.Layer < LayerUI {
+			dst = t.Rect(dst)
+		}
+		if s.Options.Plain() {
+			r.DrawSprite(s.Texture, s.Src, &dst)
+		} else {
+			o := s.Options
+			if o.Pivot != nil && s.Dst.W != 0 && s.Dst.H != 0 {
+				o.Pivot = &sdl.Point{X: o.Pivot.X * dst.W / s.Dst.W, Y: o.Pivot.Y * dst.H / s.Dst.H}
+			}
+			r.DrawSpriteEx(s.Texture, s.Src, &dst, &o)
+		}
+		q.Stats.DrawCalls++
+	}
+	q.Reset()
+}
+
+// Reset drops sprites without drawing, memory is kept for the next frame
+func (q *Queue) Reset() {
+	for i := range q.sprites {
+		q.sprites[i] = Sprite{}
+	}
+	q.sprites = q.sprites[:0]
+	clear(q.textures)
+}
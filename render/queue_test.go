@@ -0,0 +1,93 @@
+package render
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func flushed(sprites []Sprite) []Command {
+	q := NewQueue()
+	for _, s := range sprites {
+		q.Submit(s)
+	}
+	rec := NewRecorder(8, 8)
+	q.Flush(rec, nil)
+	return rec.Commands()
+}
+
+func TestQueueOrder(t *testing.T) {
+	ship, ufo, bullet := &NullTexture{File: "ship"}, &NullTexture{File: "ufo"}, &NullTexture{File: "bullet"}
+	sprite := func(tex Texture, layer Layer, z int32, seq uint64, x int32) Sprite {
+		return Sprite{Texture: tex, Dst: sdl.Rect{X: x, W: 8, H: 8}, Layer: layer, Z: z, Seq: seq}
+	}
+	sprites := []Sprite{
+		sprite(ship, LayerPlayer, 0, 1, 0),
+		sprite(ufo, LayerEnemies, 0, 5, 1),
+		sprite(bullet, LayerEnemies, 0, 3, 2),
+		sprite(ufo, LayerEnemies, 0, 2, 3),
+		sprite(bullet, LayerEnemies, 0, 4, 4),
+		sprite(ufo, LayerEnemies, 1, 6, 5),
+		sprite(bullet, LayerBullets, 0, 7, 6),
+	}
+	// Enemies group by texture, ufo has the oldest sprite so goes first
+	want := []int32{3, 1, 2, 4, 5, 6, 0}
+	got := flushed(sprites)
+	if len(got) != len(want) {
+		t.Fatalf("got %d commands want %d", len(got), len(want))
+	}
+	for i, c := range got {
+		if c.Dst.X != want[i] {
+			t.Fatalf("draw %d is sprite %d, want %d", i, c.Dst.X, want[i])
+		}
+	}
+
+	// Submit order, like map iteration, does not change the frame
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		shuffled := append([]Sprite(nil), sprites...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		if again := flushed(shuffled); !reflect.DeepEqual(again, got) {
+			t.Fatalf("shuffled submit drew %v\nwant %v", again, got)
+		}
+	}
+}
+
+func TestQueueSameSeqKeepsSubmitOrder(t *testing.T) {
+	tiles := &NullTexture{File: "tiles"}
+	var sprites []Sprite
+	for x := int32(0); x < 4; x++ {
+		sprites = append(sprites, Sprite{Texture: tiles, Dst: sdl.Rect{X: x}})
+	}
+	for i, c := range flushed(sprites) {
+		if c.Dst.X != int32(i) {
+			t.Fatalf("draw %d is tile %d", i, c.Dst.X)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	var c Cache
+	loads := 0
+	load := func() (Texture, error) {
+		loads++
+		return &NullTexture{File: "ship.png"}, nil
+	}
+	a, _ := c.Load("ship.png", load)
+	b, _ := c.Load("ship.png", load)
+	if a != b || loads != 1 {
+		t.Fatalf("second load got new texture %v, loads %d", a != b, loads)
+	}
+	if c.Release("ship.png") {
+		t.Error("first release freed shared texture")
+	}
+	if !c.Release("ship.png") || c.Len() != 0 {
+		t.Error("last release kept the texture")
+	}
+	c.Load("ship.png", load)
+	if loads != 2 {
+		t.Errorf("load after free reused freed texture")
+	}
+}
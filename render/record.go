@@ -0,0 +1,137 @@
+package render
+
+import (
+	"sync"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Op is a kind of recorded command
+type Op int
+
+const (
+	OpSetColor Op = iota
+	OpSprite
+	OpLine
+	OpRect
+	OpFillRect
+	OpPresent
+	OpSpriteEx
+)
+
+// Command is a recorded renderer call, only fields of its Op are set
+type Command struct {
+	Op      Op
+	Color   sdl.Color
+	Texture Texture
+	// Src and Dst are nil when whole texture or screen was used
+	Src, Dst *sdl.Rect
+	// Line ends
+	X1, Y1, X2, Y2 int32
+	// Options of OpSpriteEx
+	Options Options
+}
+
+// Recorder keeps draw commands for assertions, it loads textures
+// like Null but shares them by file like SDL and is safe to use from
+// game object goroutines
+type Recorder struct {
+	Null
+	mu       sync.Mutex
+	commands []Command
+	cache    Cache
+}
+
+// NewRecorder creates recorder with textures of w x h size
+func NewRecorder(w, h int32) *Recorder {
+	return &Recorder{Null: Null{TextureW: w, TextureH: h}}
+}
+
+func (rec *Recorder) LoadTexture(file string) (Texture, error) {
+	return rec.cache.Load(file, func() (Texture, error) {
+		return &NullTexture{File: file, W: rec.TextureW, H: rec.TextureH, cache: &rec.cache}, nil
+	})
+}
+
+// Textures returns number of files with a loaded texture
+func (rec *Recorder) Textures() int {
+	return rec.cache.Len()
+}
+
+func (rec *Recorder) add(c Command) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.commands = append(rec.commands, c)
+}
+
+// copyRect keeps the value as callers reuse their rects
+func copyRect(r *sdl.Rect) *sdl.Rect {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
+
+func (rec *Recorder) SetColor(c sdl.Color) {
+	rec.add(Command{Op: OpSetColor, Color: c})
+}
+
+func (rec *Recorder) DrawSprite(t Texture, src, dst *sdl.Rect) {
+	rec.add(Command{Op: OpSprite, Texture: t, Src: copyRect(src), Dst: copyRect(dst)})
+}
+
+func (rec *Recorder) DrawSpriteEx(t Texture, src, dst *sdl.Rect, o *Options) {
+	c := Command{Op: OpSpriteEx, Texture: t, Src: copyRect(src), Dst: copyRect(dst)}
+	if o != nil {
+		c.Options = *o
+		if o.Pivot != nil {
+			pivot := *o.Pivot
+			c.Options.Pivot = &pivot
+		}
+	}
+	rec.add(c)
+}
+
+func (rec *Recorder) DrawLine(x1, y1, x2, y2 int32) {
+	rec.add(Command{Op: OpLine, X1: x1, Y1: y1, X2: x2, Y2: y2})
+}
+
+func (rec *Recorder) DrawRect(r *sdl.Rect) {
+	rec.add(Command{Op: OpRect, Dst: copyRect(r)})
+}
+
+func (rec *Recorder) FillRect(r *sdl.Rect) {
+	rec.add(Command{Op: OpFillRect, Dst: copyRect(r)})
+}
+
+func (rec *Recorder) Present() {
+	rec.add(Command{Op: OpPresent})
+}
+
+// Commands returns copy of the recorded commands
+func (rec *Recorder) Commands() []Command {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Command(nil), rec.commands...)
+}
+
+// Count returns number of recorded commands of the op
+func (rec *Recorder) Count(op Op) int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	n := 0
+	for _, c := range rec.commands {
+		if c.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets recorded commands
+func (rec *Recorder) Reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.commands = nil
+}
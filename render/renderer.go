@@ -0,0 +1,47 @@
+package render
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// Texture is an image owned by a renderer
+type Texture interface {
+	// Size in pixels
+	Size() (w, h int32)
+	// Free resources
+	Free()
+}
+
+// Renderer draws sprites and shapes, coordinates are in pixels;
+// game objects and the queue draw through it, while scenes, HUD, UI,
+// text, particles and the debug overlay still take *sdl.Renderer
+type Renderer interface {
+	// SetColor sets color of lines and rects
+	SetColor(c sdl.Color)
+	// DrawSprite copies src part of the texture, nil for whole,
+	// to dst part of the screen, nil for whole
+	DrawSprite(t Texture, src, dst *sdl.Rect)
+	// DrawSpriteEx is DrawSprite rotated, flipped, tinted or flashed
+	// by the options, nil options draw it like DrawSprite
+	DrawSpriteEx(t Texture, src, dst *sdl.Rect, o *Options)
+	DrawLine(x1, y1, x2, y2 int32)
+	// DrawRect draws rect outline
+	DrawRect(r *sdl.Rect)
+	FillRect(r *sdl.Rect)
+	// Present shows the frame
+	Present()
+}
+
+// Loader creates textures from image files
+type Loader interface {
+	LoadTexture(file string) (Texture, error)
+}
+
+// Device is a renderer which can also load its textures
+type Device interface {
+	Renderer
+	Loader
+}
+
+// TextureCounter is a renderer which knows how many of its textures are alive
+type TextureCounter interface {
+	TextureCount() int
+}
@@ -0,0 +1,170 @@
+package render
+
+import (
+	"sync/atomic"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SDLTexture is a texture of the SDL renderer
+type SDLTexture struct {
+	T    *sdl.Texture
+	W, H int32
+	// White silhouette drawn over the texture by flashing sprites,
+	// nil for textures not loaded from files
+	Flash *sdl.Texture
+	// Renderer which loaded the texture, counts live textures
+	owner *SDL
+	file  string
+}
+
+// NewSDLTexture wraps SDL texture
+func NewSDLTexture(t *sdl.Texture) *SDLTexture {
+	_, _, w, h, _ := t.Query()
+	return &SDLTexture{T: t, W: w, H: h}
+}
+
+func (t *SDLTexture) Size() (int32, int32) {
+	return t.W, t.H
+}
+
+// Free drops the texture, one loaded from a file is destroyed
+// when every load of the file is freed
+func (t *SDLTexture) Free() {
+	if t.owner != nil && !t.owner.cache.Release(t.file) {
+		return
+	}
+	t.T.Destroy()
+	if t.Flash != nil {
+		t.Flash.Destroy()
+	}
+	if t.owner != nil {
+		t.owner.textures.Add(-1)
+	}
+}
+
+// SDL draws with SDL renderer
+type SDL struct {
+	R        *sdl.Renderer
+	textures atomic.Int64
+	cache    Cache
+}
+
+// NewSDL creates renderer drawing with r
+func NewSDL(r *sdl.Renderer) *SDL {
+	return &SDL{R: r}
+}
+
+// LoadTexture loads image file, loads of the same file share the texture
+func (s *SDL) LoadTexture(file string) (Texture, error) {
+	return s.cache.Load(file, func() (Texture, error) {
+		return s.load(file)
+	})
+}
+
+func (s *SDL) load(file string) (Texture, error) {
+	surface, err := img.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	defer surface.Free()
+	t, err := s.R.CreateTextureFromSurface(surface)
+	if err != nil {
+		return nil, err
+	}
+	texture := NewSDLTexture(t)
+	texture.Flash, err = s.silhouette(surface)
+	if err != nil {
+		t.Destroy()
+		return nil, err
+	}
+	texture.owner, texture.file = s, file
+	s.textures.Add(1)
+	return texture, nil
+}
+
+// silhouette creates white texture with the alpha of the surface
+func (s *SDL) silhouette(surface *sdl.Surface) (*sdl.Texture, error) {
+	white, err := surface.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer white.Free()
+	white.Lock()
+	pixels := white.Pixels()
+	for y := 0; y < int(white.H); y++ {
+		row := pixels[y*int(white.Pitch):]
+		for x := 0; x < int(white.W)*4; x += 4 {
+			row[x], row[x+1], row[x+2] = 255, 255, 255
+		}
+	}
+	white.Unlock()
+	t, err := s.R.CreateTextureFromSurface(white)
+	if err != nil {
+		return nil, err
+	}
+	t.SetBlendMode(sdl.BLENDMODE_BLEND)
+	return t, nil
+}
+
+// TextureCount returns number of loaded textures not freed yet
+func (s *SDL) TextureCount() int {
+	return int(s.textures.Load())
+}
+
+func (s *SDL) SetColor(c sdl.Color) {
+	s.R.SetDrawColor(c.R, c.G, c.B, c.A)
+}
+
+// DrawSprite draws only textures of this renderer
+func (s *SDL) DrawSprite(t Texture, src, dst *sdl.Rect) {
+	if t, ok := t.(*SDLTexture); ok {
+		s.R.Copy(t.T, src, dst)
+	}
+}
+
+func (s *SDL) DrawSpriteEx(t Texture, src, dst *sdl.Rect, o *Options) {
+	texture, ok := t.(*SDLTexture)
+	if !ok {
+		return
+	}
+	if o.Plain() {
+		s.R.Copy(texture.T, src, dst)
+		return
+	}
+	var flip sdl.RendererFlip
+	if o.Flip&FlipHorizontal != 0 {
+		flip |= sdl.FLIP_HORIZONTAL
+	}
+	if o.Flip&FlipVertical != 0 {
+		flip |= sdl.FLIP_VERTICAL
+	}
+	mod := o.Mod()
+	texture.T.SetColorMod(mod.R, mod.G, mod.B)
+	texture.T.SetAlphaMod(mod.A)
+	s.R.CopyEx(texture.T, src, dst, o.Angle, o.Pivot, flip)
+	texture.T.SetColorMod(255, 255, 255)
+	texture.T.SetAlphaMod(255)
+
+	if o.Flash > 0 && texture.Flash != nil {
+		texture.Flash.SetAlphaMod(uint8(min(o.Flash, 1) * float32(mod.A)))
+		s.R.CopyEx(texture.Flash, src, dst, o.Angle, o.Pivot, flip)
+	}
+}
+
+func (s *SDL) DrawLine(x1, y1, x2, y2 int32) {
+	s.R.DrawLine(x1, y1, x2, y2)
+}
+
+func (s *SDL) DrawRect(r *sdl.Rect) {
+	s.R.DrawRect(r)
+}
+
+func (s *SDL) FillRect(r *sdl.Rect) {
+	s.R.FillRect(r)
+}
+
+func (s *SDL) Present() {
+	s.R.Present()
+}
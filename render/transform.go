@@ -0,0 +1,15 @@
+package render
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// Transform maps world coordinates to the screen, camera.Camera is one
+type Transform interface {
+	Rect(r sdl.Rect) sdl.Rect
+	FRect(r sdl.FRect) sdl.FRect
+}
+
+// Identity leaves coordinates as they are
+type Identity struct{}
+
+func (Identity) Rect(r sdl.Rect) sdl.Rect    { return r }
+func (Identity) FRect(r sdl.FRect) sdl.FRect { return r }
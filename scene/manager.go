@@ -0,0 +1,159 @@
+package scene
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type transitionKind int
+
+const (
+	push transitionKind = iota
+	pop
+	replace
+	reset
+	quit
+)
+
+type transition struct {
+	kind transitionKind
+	name Name
+}
+
+// Manager holds the stack of active scenes
+type Manager struct {
+	factories map[Name]Factory
+	stack     []Scene
+	pending   []transition
+	done      bool
+}
+
+// NewManager creates empty scene manager
+func NewManager() *Manager {
+	return &Manager{
+		factories: make(map[Name]Factory),
+	}
+}
+
+// Register adds scene factory under the name
+func (m *Manager) Register(name Name, factory Factory) {
+	m.factories[name] = factory
+}
+
+// Push places new scene on top of the stack
+func (m *Manager) Push(name Name) {
+	m.pending = append(m.pending, transition{kind: push, name: name})
+}
+
+// Pop removes top scene
+func (m *Manager) Pop() {
+	m.pending = append(m.pending, transition{kind: pop})
+}
+
+// Replace swaps top scene with new one
+func (m *Manager) Replace(name Name) {
+	m.pending = append(m.pending, transition{kind: replace, name: name})
+}
+
+// Reset clears the stack and starts from the scene
+func (m *Manager) Reset(name Name) {
+	m.pending = append(m.pending, transition{kind: reset, name: name})
+}
+
+// Quit clears the stack and stops the game
+func (m *Manager) Quit() {
+	m.pending = append(m.pending, transition{kind: quit})
+}
+
+// Done reports whether there is nothing left to run
+func (m *Manager) Done() bool {
+	return m.done
+}
+
+// Top returns current scene or nil
+func (m *Manager) Top() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// HandleEvent passes event to the top scene
+func (m *Manager) HandleEvent(e sdl.Event) {
+	if top := m.Top(); top != nil {
+		top.HandleEvent(e)
+	}
+	m.apply()
+}
+
+// Update advances the top scene and applies requested transitions
+func (m *Manager) Update() {
+	if top := m.Top(); top != nil {
+		top.Update()
+	}
+	m.apply()
+}
+
+// Draw renders the stack from bottom to top starting
+// with the topmost scene that is not an overlay
+func (m *Manager) Draw(r *sdl.Renderer) {
+	first := len(m.stack) - 1
+	for first > 0 {
+		if o, ok := m.stack[first].(Overlay); !ok || !o.IsOverlay() {
+			break
+		}
+		first--
+	}
+	for _, s := range m.stack[max(first, 0):] {
+		s.Draw(r)
+	}
+}
+
+// apply runs transitions requested since the last call,
+// so scenes never change the stack while they are running
+func (m *Manager) apply() {
+	for len(m.pending) > 0 {
+		t := m.pending[0]
+		m.pending = m.pending[1:]
+		switch t.kind {
+		case push:
+			m.enter(t.name)
+		case pop:
+			m.exit()
+		case replace:
+			m.exit()
+			m.enter(t.name)
+		case reset:
+			for len(m.stack) > 0 {
+				m.exit()
+			}
+			m.enter(t.name)
+		case quit:
+			for len(m.stack) > 0 {
+				m.exit()
+			}
+			m.pending = nil
+		}
+	}
+	if len(m.stack) == 0 {
+		m.done = true
+	}
+}
+
+func (m *Manager) enter(name Name) {
+	factory, ok := m.factories[name]
+	if !ok {
+		panic("scene: unknown scene " + string(name))
+	}
+	s := factory()
+	m.stack = append(m.stack, s)
+	s.Enter()
+}
+
+func (m *Manager) exit() {
+	top := m.Top()
+	if top == nil {
+		return
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	top.Exit()
+}
@@ -0,0 +1,196 @@
+package scene
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// fake records calls of all scenes into a shared log
+type fake struct {
+	name    Name
+	overlay bool
+	log     *[]string
+	// Runs during Update, to request transitions from inside a scene
+	onUpdate func()
+}
+
+func (f *fake) record(call string) {
+	*f.log = append(*f.log, string(f.name)+"."+call)
+}
+
+func (f *fake) Enter()                { f.record("enter") }
+func (f *fake) Exit()                 { f.record("exit") }
+func (f *fake) HandleEvent(sdl.Event) { f.record("event") }
+func (f *fake) Draw(*sdl.Renderer)    { f.record("draw") }
+func (f *fake) IsOverlay() bool       { return f.overlay }
+
+func (f *fake) Update() {
+	f.record("update")
+	if f.onUpdate != nil {
+		f.onUpdate()
+	}
+}
+
+// newManager registers scenes a, b and overlay o, hooks lets tests
+// set what a scene does on Update
+func newManager(log *[]string, hooks map[Name]func()) *Manager {
+	m := NewManager()
+	for _, name := range []Name{"a", "b", "o"} {
+		m.Register(name, func() Scene {
+			return &fake{name: name, overlay: name == "o", log: log, onUpdate: hooks[name]}
+		})
+	}
+	return m
+}
+
+func expectLog(t *testing.T, log *[]string, want ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(*log, want) && !(len(*log) == 0 && len(want) == 0) {
+		t.Errorf("calls %v\nwant %v", *log, want)
+	}
+	*log = nil
+}
+
+func TestTransitionsArePending(t *testing.T) {
+	var log []string
+	m := newManager(&log, nil)
+	m.Push("a")
+	m.Push("b")
+	if m.Top() != nil {
+		t.Fatal("push applied before Update")
+	}
+	expectLog(t, &log)
+
+	m.Update()
+	// No scene ran yet, transitions are applied in order
+	expectLog(t, &log, "a.enter", "b.enter")
+	if top := m.Top().(*fake); top.name != "b" {
+		t.Errorf("top is %s", top.name)
+	}
+
+	m.HandleEvent(nil)
+	expectLog(t, &log, "b.event")
+}
+
+func TestStackOperations(t *testing.T) {
+	var log []string
+	m := newManager(&log, nil)
+	m.Reset("a")
+	m.Update()
+	expectLog(t, &log, "a.enter")
+
+	m.Push("b")
+	m.Update()
+	expectLog(t, &log, "a.update", "b.enter")
+
+	m.Replace("a")
+	m.Update()
+	expectLog(t, &log, "b.update", "b.exit", "a.enter")
+
+	m.Pop()
+	m.Update()
+	expectLog(t, &log, "a.update", "a.exit")
+	if top := m.Top().(*fake); top.name != "a" || m.Done() {
+		t.Errorf("after pop top is %s, done %v", top.name, m.Done())
+	}
+
+	// Reset exits the whole stack from the top
+	m.Push("b")
+	m.Update()
+	log = nil
+	m.Reset("b")
+	m.Update()
+	expectLog(t, &log, "b.update", "b.exit", "a.exit", "b.enter")
+}
+
+func TestTransitionsFromScene(t *testing.T) {
+	var log []string
+	var m *Manager
+	m = newManager(&log, map[Name]func(){
+		"a": func() { m.Push("o") },
+	})
+	m.Push("a")
+	m.Update()
+	log = nil
+
+	// Scene asks for a push while it runs, it is applied after it
+	m.Update()
+	expectLog(t, &log, "a.update", "o.enter")
+}
+
+func TestDrawThroughOverlays(t *testing.T) {
+	var log []string
+	m := newManager(&log, nil)
+	m.Push("a")
+	m.Push("b")
+	m.Push("o")
+	m.Push("o")
+	m.Update()
+	log = nil
+
+	// Overlays show the first opaque scene below them, not deeper ones
+	m.Draw(nil)
+	expectLog(t, &log, "b.draw", "o.draw", "o.draw")
+
+	m.Pop()
+	m.Pop()
+	m.Update()
+	log = nil
+	m.Draw(nil)
+	expectLog(t, &log, "b.draw")
+
+	// Overlay at the bottom is drawn alone
+	m.Reset("o")
+	m.Update()
+	log = nil
+	m.Draw(nil)
+	expectLog(t, &log, "o.draw")
+}
+
+func TestQuit(t *testing.T) {
+	var log []string
+	m := newManager(&log, nil)
+	m.Push("a")
+	m.Push("b")
+	m.Update()
+	log = nil
+
+	// Transitions after quit are dropped
+	m.Quit()
+	m.Push("a")
+	m.Update()
+	expectLog(t, &log, "b.update", "b.exit", "a.exit")
+	if !m.Done() || m.Top() != nil {
+		t.Errorf("after quit done %v, top %v", m.Done(), m.Top())
+	}
+	m.Update()
+	m.Draw(nil)
+	expectLog(t, &log)
+}
+
+func TestPopLastSceneIsDone(t *testing.T) {
+	var log []string
+	m := newManager(&log, nil)
+	m.Push("a")
+	m.Update()
+	m.Pop()
+	m.Pop()
+	m.Update()
+	if !m.Done() {
+		t.Error("empty stack is not done")
+	}
+}
+
+func TestUnknownScenePanics(t *testing.T) {
+	var log []string
+	m := newManager(&log, nil)
+	m.Push("missing")
+	defer func() {
+		if recover() == nil {
+			t.Error("unknown scene did not panic")
+		}
+	}()
+	m.Update()
+}
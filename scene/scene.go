@@ -0,0 +1,41 @@
+package scene
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Name identifies registered scene
+type Name string
+
+// Known scenes
+const (
+	Title      Name = "title"
+	Options    Name = "options"
+	Playing    Name = "playing"
+	Paused     Name = "paused"
+	GameOver   Name = "gameover"
+	HighScores Name = "highscores"
+)
+
+// Scene is a single game screen
+type Scene interface {
+	// Enter is called when scene is placed on the stack
+	Enter()
+	// Exit is called when scene is removed from the stack
+	Exit()
+	// HandleEvent receives events while scene is on top
+	HandleEvent(e sdl.Event)
+	// Update advances scene state while scene is on top
+	Update()
+	// Draw renders scene, overlays are drawn over scenes below them
+	Draw(r *sdl.Renderer)
+}
+
+// Overlay is a scene that lets scenes below it be seen
+type Overlay interface {
+	Scene
+	IsOverlay() bool
+}
+
+// Factory creates fresh scene instance
+type Factory func() Scene
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"sdl_learn/audio"
+	"sdl_learn/gobject"
+	"sdl_learn/hud"
+	"sdl_learn/inputs"
+	"sdl_learn/scene"
+	"sdl_learn/text"
+	"sdl_learn/ui"
+	"sort"
+	"strconv"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Best results of the session, highest first
+var highScores []int
+
+// Score of the last finished game
+var lastScore int
+
+const maxHighScores = 10
+
+func addHighScore(score int) {
+	highScores = append(highScores, score)
+	sort.Sort(sort.Reverse(sort.IntSlice(highScores)))
+	if len(highScores) > maxHighScores {
+		highScores = highScores[:maxHighScores]
+	}
+}
+
+// titleScene is the main menu
+type titleScene struct {
+	menu *ui.Menu
+}
+
+func newTitleScene() scene.Scene {
+	return &titleScene{
+		menu: ui.NewMenu(WindowTitle, itemStart, itemOptions, itemHighScores, itemQuit),
+	}
+}
+
+func (s *titleScene) Enter() {
+	sounds.Music.Play(audio.MusicMenu)
+}
+
+func (s *titleScene) Exit() {}
+
+func (s *titleScene) HandleEvent(e sdl.Event) {
+	if inputs.Pressed(e, sdl.SCANCODE_ESCAPE) {
+		scenes.Quit()
+		return
+	}
+	if i := s.menu.HandleEvent(e); i >= 0 {
+		switch s.menu.Items[i] {
+		case itemStart:
+			scenes.Replace(scene.Playing)
+		case itemOptions:
+			scenes.Push(scene.Options)
+		case itemHighScores:
+			scenes.Push(scene.HighScores)
+		case itemQuit:
+			scenes.Quit()
+		}
+	}
+}
+
+func (s *titleScene) Update() {}
+
+func (s *titleScene) Draw(r *sdl.Renderer) {
+	s.menu.Draw(r, font)
+}
+
+// Lives at the start of the game
+const startLives = 3
+
+// Every bossWave-th wave has a boss taking bossHP hits
+const (
+	bossWave = 3
+	bossHP   = 5
+)
+
+// Whether FPS counter is shown, kept between games
+var showFPS bool
+
+// playingScene holds the state of a single game
+type playingScene struct {
+	player  *gobject.Gobject
+	manager *gobject.Manager
+	hud     *hud.HUD
+	level   *level
+	// Boss of the current wave, nil when there is none alive
+	boss        *gobject.Gobject
+	left, right int32
+	lives, wave int
+}
+
+func newPlayingScene() scene.Scene {
+	return &playingScene{}
+}
+
+func (s *playingScene) Enter() {
+	s.level = loadLevel(screen, LevelFile)
+
+	player := s.newPlayer()
+	player.Destroyed = make(map[string]int)
+
+	enemies := make(map[string]*gobject.Gobject)
+	for _, pos := range s.level.Spawns(classUfo, defaultUfoSpawns) {
+		ufo := NewUfo(screen, pos.X, pos.Y)
+		enemies[ufo.Id] = ufo
+	}
+
+	s.player = player
+	s.manager = gobject.NewManager(player, screen, enemies, make(map[string]*gobject.Gobject))
+	s.hud = hud.NewHUD(font, ui.White)
+	effects.Attach(exhaust(func() *gobject.Gobject { return s.player }))
+	// Camera follows the player around the whole map
+	cam.Bounds = s.level.Bounds()
+	cam.Zoom = s.level.Zoom()
+	cam.Follow(func() (float64, float64) {
+		return float64(s.player.X + s.player.Width/2), float64(s.player.Y + s.player.Height/2)
+	})
+	cam.LookAt(float64(player.X+player.Width/2), float64(player.Y+player.Height/2))
+	s.lives = startLives
+	s.wave = 1
+	sounds.Music.Play(audio.MusicGameplay)
+}
+
+// newPlayer creates player at the map spawn point, it can fly
+// over the whole map
+func (s *playingScene) newPlayer() *gobject.Gobject {
+	player := NewPlayer(screen)
+	bounds := s.level.Bounds()
+	player.MaxX, player.MaxY = int32(bounds.W), int32(bounds.H)
+	if spawns := s.level.Spawns(classPlayer, nil); len(spawns) > 0 {
+		player.X, player.Y = spawns[0].X, spawns[0].Y
+	}
+	return player
+}
+
+func (s *playingScene) Exit() {
+	for _, val := range s.manager.Enemies {
+		val.Free()
+	}
+	for _, val := range s.manager.Bullets {
+		val.Free()
+	}
+	s.player.Free()
+	s.hud.Free()
+	s.level.Free()
+	effects.Clear()
+	cam.Follow(nil)
+	cam.Reset()
+	cam.Bounds, cam.Zoom = windowBounds, 1
+	debugOverlay.Reset()
+	sounds.SetListener(WindowWidth/2, WindowHeight/2)
+}
+
+func (s *playingScene) HandleEvent(e sdl.Event) {
+	switch {
+	case inputs.IsPause(e):
+		scenes.Push(scene.Paused)
+	case inputs.Pressed(e, sdl.SCANCODE_F1):
+		showFPS = !showFPS
+	case inputs.Pressed(e, debugKey):
+		debugOverlay.Toggle()
+	}
+}
+
+func (s *playingScene) Update() {
+	player, manager := s.player, s.manager
+
+	score := 0
+	for _, v := range player.Destroyed {
+		score += v
+	}
+	player.Score = score
+	if player.Score%400 == 0 && len(manager.Enemies) == 0 {
+		s.wave++
+		ufos := 4
+		// Boss takes place of the last ufo so the wave is still worth 400
+		if s.wave%bossWave == 0 {
+			ufos--
+			s.boss = NewBoss(screen, int32(4*200), int32(4*100))
+			manager.Enemies[s.boss.Id] = s.boss
+		}
+		for i := 1; i <= ufos; i++ {
+			newUfo := NewUfo(screen, int32(i*200), int32(i*100))
+			manager.Enemies[newUfo.Id] = newUfo
+		}
+		sounds.Play(audio.Pickup)
+	}
+
+	if !player.IsMoving {
+		s.lives--
+		if s.lives <= 0 {
+			lastScore = player.Score
+			scenes.Push(scene.GameOver)
+			return
+		}
+		// Destroyed player frees its own texture, so start with a new one
+		respawned := s.newPlayer()
+		respawned.Destroyed = player.Destroyed
+		respawned.Score = player.Score
+		s.player, manager.PlayerObj = respawned, respawned
+		player = respawned
+	}
+
+	if s.boss != nil && manager.Enemies[s.boss.Id] == nil {
+		s.boss = nil
+	}
+	if s.boss != nil {
+		sounds.Music.Play(audio.MusicBoss)
+		sounds.Music.SetIntensity(1)
+	} else {
+		sounds.Music.Play(audio.MusicGameplay)
+		sounds.Music.SetIntensity(musicIntensity(len(manager.Enemies)))
+	}
+	sounds.SetListener(player.X+player.Width/2, player.Y+player.Height/2)
+
+	player.Update(screen)
+	if manager.ScanShoot() {
+		bullet := NewBullet(screen, player.X, player.Y)
+		manager.Bullets[bullet.Id] = bullet
+	}
+
+	for _, val := range manager.Enemies {
+		if val.IsMoving {
+			val.Face(player.X+player.Width/2, player.Y+player.Height/2)
+		}
+	}
+
+	if s.left < WindowWidth/4 {
+		for _, val := range manager.Enemies {
+			if val.IsMoving && val.X > gobject.EnemyMargin {
+				val.LeftMoving(screen, player)
+				s.left++
+			}
+		}
+	} else if s.left >= WindowWidth/4 && s.right < WindowWidth-gobject.EnemyMargin {
+		for _, val := range manager.Enemies {
+			if val.IsMoving && val.X < WindowWidth-gobject.EnemyMargin {
+				val.RightMoving(screen, player)
+				s.right++
+			}
+		}
+	} else {
+		if s.right >= WindowWidth-gobject.EnemyMargin && s.left >= WindowWidth/4 {
+			s.left, s.right = -WindowWidth, 0
+		}
+	}
+
+	for key, val := range manager.Bullets {
+		if val.Y > 0 && val.IsMoving {
+			val.UpMoving(manager.R, manager.Enemies, manager.Bullets, manager.PlayerObj)
+		} else if val.Y <= 0 || !val.IsMoving {
+			val.Free()
+			delete(manager.Bullets, key)
+		}
+	}
+
+	effects.Update(frameDelta / 1000)
+	cam.Update(frameDelta / 1000)
+	debugOverlay.Frame(frameDelta, debugObjects(manager))
+}
+
+func (s *playingScene) Draw(r *sdl.Renderer) {
+	s.level.Submit(queue, cam.View())
+	s.player.Submit(queue)
+	for _, val := range s.manager.Enemies {
+		val.Submit(queue)
+	}
+	for _, val := range s.manager.Bullets {
+		val.Submit(queue)
+	}
+	queue.Flush(screen, cam)
+	effectsRenderer.Draw(r, effects, cam)
+
+	highScore := 0
+	if len(highScores) > 0 {
+		highScore = highScores[0]
+	}
+	s.hud.ShowFPS = showFPS
+	s.hud.Set(s.player.Score, highScore, s.lives, s.wave)
+	s.hud.Frame()
+	s.hud.Draw(r)
+	debugOverlay.Draw(r, cam, debugObjects(s.manager), debugStats(s.manager))
+}
+
+// Options of the menus
+const (
+	itemStart      = "Start"
+	itemOptions    = "Options"
+	itemResume     = "Resume"
+	itemRestart    = "Restart"
+	itemHighScores = "High Scores"
+	itemQuit       = "Quit"
+)
+
+// pausedScene freezes the game below it
+type pausedScene struct {
+	menu *ui.Menu
+}
+
+func newPausedScene() scene.Scene {
+	return &pausedScene{
+		menu: ui.NewMenu("PAUSED", itemResume, itemRestart, itemQuit),
+	}
+}
+
+func (s *pausedScene) Enter() {}
+
+func (s *pausedScene) Exit() {}
+
+func (s *pausedScene) HandleEvent(e sdl.Event) {
+	if inputs.IsPause(e) {
+		scenes.Pop()
+		return
+	}
+	if i := s.menu.HandleEvent(e); i >= 0 {
+		switch s.menu.Items[i] {
+		case itemResume:
+			scenes.Pop()
+		case itemRestart:
+			scenes.Reset(scene.Playing)
+		case itemQuit:
+			scenes.Reset(scene.Title)
+		}
+	}
+}
+
+func (s *pausedScene) Update() {}
+
+func (s *pausedScene) IsOverlay() bool { return true }
+
+func (s *pausedScene) Draw(r *sdl.Renderer) {
+	ui.Dim(r, ui.DimColor)
+	s.menu.Draw(r, font)
+}
+
+// gameOverScene shows the loss and records the score
+type gameOverScene struct {
+	menu *ui.Menu
+}
+
+func newGameOverScene() scene.Scene {
+	return &gameOverScene{
+		menu: ui.NewMenu("GAME OVER", itemRestart, itemHighScores, itemQuit),
+	}
+}
+
+func (s *gameOverScene) Enter() {
+	addHighScore(lastScore)
+	s.menu.Title = "GAME OVER | SCORE " + strconv.Itoa(lastScore)
+}
+
+func (s *gameOverScene) Exit() {}
+
+func (s *gameOverScene) HandleEvent(e sdl.Event) {
+	if i := s.menu.HandleEvent(e); i >= 0 {
+		switch s.menu.Items[i] {
+		case itemRestart:
+			scenes.Reset(scene.Playing)
+		case itemHighScores:
+			scenes.Reset(scene.HighScores)
+		case itemQuit:
+			scenes.Reset(scene.Title)
+		}
+	}
+}
+
+func (s *gameOverScene) Update() {}
+
+func (s *gameOverScene) IsOverlay() bool { return true }
+
+func (s *gameOverScene) Draw(r *sdl.Renderer) {
+	ui.Dim(r, ui.DimColor)
+	s.menu.Draw(r, font)
+}
+
+// highScoresScene lists the best results
+type highScoresScene struct{}
+
+func newHighScoresScene() scene.Scene {
+	return &highScoresScene{}
+}
+
+func (s *highScoresScene) Enter() {
+	sounds.Music.Play(audio.MusicMenu)
+}
+
+func (s *highScoresScene) Exit() {}
+
+func (s *highScoresScene) HandleEvent(e sdl.Event) {
+	if inputs.Pressed(e, sdl.SCANCODE_RETURN) || inputs.Pressed(e, sdl.SCANCODE_ESCAPE) {
+		scenes.Reset(scene.Title)
+	}
+}
+
+func (s *highScoresScene) Update() {}
+
+func (s *highScoresScene) Draw(r *sdl.Renderer) {
+	x, _ := ui.Center(r)
+	line := font.LineHeight()
+	y := line
+	text.DrawAligned(r, font, "HIGH SCORES", x, y, text.Center, ui.Yellow)
+	y += line * 2
+	if len(highScores) == 0 {
+		text.DrawAligned(r, font, "no games yet", x, y, text.Center, ui.Grey)
+	}
+	for i, score := range highScores {
+		text.DrawAligned(r, font, strconv.Itoa(i+1)+". "+strconv.Itoa(score), x, y, text.Center, ui.White)
+		y += line
+	}
+}
@@ -0,0 +1,57 @@
+// Package screenshot captures rendered frames to PNG files
+package screenshot
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Capture reads the playfield of the current frame, call it after
+// drawing and before Present as the back buffer is undefined after it
+func Capture(r *sdl.Renderer) (*image.NRGBA, error) {
+	// ReadPixels reads the viewport which is kept in output pixels
+	viewport := r.GetViewport()
+	scaleX, scaleY := r.GetScale()
+	w, h := int(float32(viewport.W)*scaleX), int(float32(viewport.H)*scaleY)
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if err := r.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, unsafe.Pointer(&img.Pix[0]), img.Stride); err != nil {
+		return nil, err
+	}
+	Opaque(img)
+	return img, nil
+}
+
+// Opaque sets full alpha, frames are opaque but renderers
+// may leave alpha undefined
+func Opaque(img *image.NRGBA) {
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+}
+
+// Save writes PNG file creating its directory
+func Save(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Path returns file name in dir for a screenshot taken at t
+func Path(dir string, t time.Time) string {
+	return filepath.Join(dir, "screenshot-"+t.Format("20060102-150405.000")+".png")
+}
@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"strings"
+)
+
+// Difficulty changes how often enemies shoot
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Normal
+	Hard
+)
+
+var difficultyNames = []string{"Easy", "Normal", "Hard"}
+
+// Valid reports whether difficulty is known
+func (d Difficulty) Valid() bool {
+	return d >= Easy && d <= Hard
+}
+
+// String returns human readable difficulty name
+func (d Difficulty) String() string {
+	if !d.Valid() {
+		return "Unknown"
+	}
+	return difficultyNames[d]
+}
+
+// Next returns following difficulty, wrapping around
+func (d Difficulty) Next() Difficulty {
+	return (d + 1) % (Hard + 1)
+}
+
+// Prev returns previous difficulty, wrapping around
+func (d Difficulty) Prev() Difficulty {
+	return (d + Hard) % (Hard + 1)
+}
+
+// MarshalText stores difficulty by name
+func (d Difficulty) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(d.String())), nil
+}
+
+// UnmarshalText reads difficulty name, unknown name gives
+// invalid difficulty to be replaced by Sanitize
+func (d *Difficulty) UnmarshalText(data []byte) error {
+	*d = -1
+	for i, name := range difficultyNames {
+		if strings.EqualFold(name, string(data)) {
+			*d = Difficulty(i)
+		}
+	}
+	return nil
+}
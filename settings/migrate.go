@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// migrations upgrade raw settings from version i to i+1,
+// append a function here whenever Version is increased
+var migrations = []func(raw map[string]any) error{
+	migrateV0,
+}
+
+// migrate brings settings file data to the current version,
+// files without version are version 0
+func migrate(data []byte) ([]byte, error) {
+	raw := make(map[string]any)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	version := 0
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > Version {
+		return nil, fmt.Errorf("version %d is newer than supported %d", version, Version)
+	}
+	if version < 0 {
+		return nil, fmt.Errorf("bad version %d", version)
+	}
+	if version == Version {
+		return data, nil
+	}
+	for ; version < Version; version++ {
+		if err := migrations[version](raw); err != nil {
+			return nil, fmt.Errorf("migrate from version %d: %w", version, err)
+		}
+	}
+	raw["version"] = Version
+	return json.Marshal(raw)
+}
+
+// migrateV0 moves window options and volume of unversioned files,
+// which kept them at the top level, into their sections
+func migrateV0(raw map[string]any) error {
+	window, _ := raw["window"].(map[string]any)
+	if window == nil {
+		window = make(map[string]any)
+	}
+	for _, key := range []string{"width", "height", "fullscreen", "fps"} {
+		if v, ok := raw[key]; ok {
+			window[key] = v
+			delete(raw, key)
+		}
+	}
+	raw["window"] = window
+
+	if v, ok := raw["volume"]; ok {
+		volume, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("bad volume %v", v)
+		}
+		audio, _ := raw["audio"].(map[string]any)
+		if audio == nil {
+			audio = make(map[string]any)
+		}
+		audio["master"] = volume
+		raw["audio"] = audio
+		delete(raw, "volume")
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Version of the settings file layout written by this build
+const Version = 1
+
+// Application directory inside the user config dir
+const appDir = "sdl_learn"
+
+// File name of the settings file
+const fileName = "settings.json"
+
+// Window holds display options
+type Window struct {
+	Width      int32 `json:"width"`
+	Height     int32 `json:"height"`
+	Fullscreen bool  `json:"fullscreen"`
+	// Scale playfield by whole numbers only, otherwise it is letterboxed
+	IntegerScale bool   `json:"integer_scale"`
+	FPS          uint32 `json:"fps"`
+	// Rendering backend, read at startup
+	Renderer string `json:"renderer"`
+}
+
+// Rendering backends
+const (
+	RendererSDL    = "sdl"
+	RendererOpenGL = "opengl"
+)
+
+// Audio holds volumes in percent
+type Audio struct {
+	Master  int `json:"master"`
+	Music   int `json:"music"`
+	Effects int `json:"effects"`
+}
+
+// Gameplay holds speeds and delays of game objects
+type Gameplay struct {
+	Difficulty Difficulty `json:"difficulty"`
+	// Pixels per move of the player ship
+	PlayerSpeed int32 `json:"player_speed"`
+	// Pixels per move of the player bullet
+	BulletSpeed int32 `json:"bullet_speed"`
+	// Pixels per move of an enemy
+	EnemySpeed int32 `json:"enemy_speed"`
+	// Frames skipped between two shots
+	ShootDelay int `json:"shoot_delay"`
+}
+
+// Graphics holds post-processing options
+type Graphics struct {
+	// Effects applied to the whole frame, in this order
+	PostEffects []string `json:"post_effects"`
+	// Color grading lookup image, 256x16 strip of 16 blue slices,
+	// identity when empty
+	LUT string `json:"lut"`
+}
+
+// Recording holds gameplay capture options
+type Recording struct {
+	// Export format, gif or png frames
+	Format string `json:"format"`
+	// Longest segment recorded with the record key
+	MaxSeconds int `json:"max_seconds"`
+	// Seconds always kept for the replay key, 0 turns replay off
+	ReplaySeconds int `json:"replay_seconds"`
+	// Frames dropped after each recorded one
+	FrameSkip int `json:"frame_skip"`
+	// Frames are made this many times smaller
+	Scale int `json:"scale"`
+}
+
+// Post-processing effects
+const (
+	EffectCRT      = "crt"
+	EffectBloom    = "bloom"
+	EffectVignette = "vignette"
+	EffectGrade    = "grade"
+)
+
+// Effects lists known post-processing effects
+var Effects = []string{EffectCRT, EffectBloom, EffectVignette, EffectGrade}
+
+// Settings is the whole configuration
+type Settings struct {
+	Version int    `json:"version"`
+	Window  Window `json:"window"`
+	Audio   Audio  `json:"audio"`
+	// Key names by action, see inputs package
+	Controls  map[string]string `json:"controls"`
+	Gameplay  Gameplay          `json:"gameplay"`
+	Graphics  Graphics          `json:"graphics"`
+	Recording Recording         `json:"recording"`
+}
+
+// ValidKey reports whether key name is known, set by the inputs
+// package owner as settings don't depend on SDL, nil accepts any name
+var ValidKey func(name string) bool
+
+// Current is used by all packages, replaced on Load
+var Current = Default()
+
+// Default returns settings used when file is missing or value is invalid
+func Default() *Settings {
+	return &Settings{
+		Version: Version,
+		Window: Window{
+			Width:    1280,
+			Height:   720,
+			FPS:      60,
+			Renderer: RendererSDL,
+		},
+		Audio: Audio{
+			Master:  100,
+			Music:   80,
+			Effects: 100,
+		},
+		Controls: map[string]string{
+			"left":  "Left",
+			"right": "Right",
+			"shoot": "Space",
+			"pause": "Pause",
+		},
+		Gameplay: Gameplay{
+			Difficulty:  Normal,
+			PlayerSpeed: 20,
+			BulletSpeed: 70,
+			EnemySpeed:  2,
+			ShootDelay:  3,
+		},
+		Recording: Recording{
+			Format:     "gif",
+			MaxSeconds: 20,
+			FrameSkip:  1,
+			Scale:      2,
+		},
+	}
+}
+
+// Path returns settings file location in the XDG config dir
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDir, fileName), nil
+}
+
+// Load reads settings file, missing file gives defaults,
+// values missing in the file keep their defaults
+// and invalid ones are replaced, in that case both settings
+// and error describing replaced values are returned
+func Load(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err = migrate(data)
+	if err != nil {
+		return nil, fmt.Errorf("settings: %s: %w", path, err)
+	}
+	s := Default()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("settings: %s: %w", path, err)
+	}
+	return s, s.Sanitize()
+}
+
+// Save writes settings file, creating its directory
+func Save(path string, s *Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	s.Version = Version
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write next to the target and rename, so crash never leaves half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Sanitize replaces invalid values with defaults,
+// returned error lists every replaced value
+func (s *Settings) Sanitize() error {
+	def := Default()
+	var errs []error
+	fix := func(ok bool, name string, value any, reset func()) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("invalid %s %v, using default", name, value))
+			reset()
+		}
+	}
+	fix(s.Window.Width >= 320 && s.Window.Width <= 7680, "window width", s.Window.Width, func() { s.Window.Width = def.Window.Width })
+	fix(s.Window.Height >= 240 && s.Window.Height <= 4320, "window height", s.Window.Height, func() { s.Window.Height = def.Window.Height })
+	fix(s.Window.FPS >= 10 && s.Window.FPS <= 240, "fps", s.Window.FPS, func() { s.Window.FPS = def.Window.FPS })
+	fix(s.Window.Renderer == RendererSDL || s.Window.Renderer == RendererOpenGL, "renderer", s.Window.Renderer, func() { s.Window.Renderer = def.Window.Renderer })
+	fix(percent(s.Audio.Master), "master volume", s.Audio.Master, func() { s.Audio.Master = def.Audio.Master })
+	fix(percent(s.Audio.Music), "music volume", s.Audio.Music, func() { s.Audio.Music = def.Audio.Music })
+	fix(percent(s.Audio.Effects), "effects volume", s.Audio.Effects, func() { s.Audio.Effects = def.Audio.Effects })
+	if s.Controls == nil {
+		s.Controls = make(map[string]string)
+	}
+	for action, key := range def.Controls {
+		name := s.Controls[action]
+		ok := name != "" && (ValidKey == nil || ValidKey(name))
+		fix(ok, "key for "+action, name, func() { s.Controls[action] = key })
+	}
+	for action := range s.Controls {
+		if _, ok := def.Controls[action]; !ok {
+			errs = append(errs, fmt.Errorf("unknown action %q, skipped", action))
+			delete(s.Controls, action)
+		}
+	}
+	g := &s.Gameplay
+	fix(g.Difficulty.Valid(), "difficulty", g.Difficulty, func() { g.Difficulty = def.Gameplay.Difficulty })
+	fix(g.PlayerSpeed > 0 && g.PlayerSpeed <= 200, "player speed", g.PlayerSpeed, func() { g.PlayerSpeed = def.Gameplay.PlayerSpeed })
+	fix(g.BulletSpeed > 0 && g.BulletSpeed <= 200, "bullet speed", g.BulletSpeed, func() { g.BulletSpeed = def.Gameplay.BulletSpeed })
+	fix(g.EnemySpeed > 0 && g.EnemySpeed <= 100, "enemy speed", g.EnemySpeed, func() { g.EnemySpeed = def.Gameplay.EnemySpeed })
+	fix(g.ShootDelay >= 0 && g.ShootDelay <= 60, "shoot delay", g.ShootDelay, func() { g.ShootDelay = def.Gameplay.ShootDelay })
+	rec := &s.Recording
+	fix(rec.Format == "gif" || rec.Format == "png", "recording format", rec.Format, func() { rec.Format = def.Recording.Format })
+	fix(rec.MaxSeconds > 0 && rec.MaxSeconds <= 300, "recording length", rec.MaxSeconds, func() { rec.MaxSeconds = def.Recording.MaxSeconds })
+	fix(rec.ReplaySeconds >= 0 && rec.ReplaySeconds <= 60, "replay length", rec.ReplaySeconds, func() { rec.ReplaySeconds = def.Recording.ReplaySeconds })
+	fix(rec.FrameSkip >= 0 && rec.FrameSkip <= 10, "frame skip", rec.FrameSkip, func() { rec.FrameSkip = def.Recording.FrameSkip })
+	fix(rec.Scale >= 1 && rec.Scale <= 8, "recording scale", rec.Scale, func() { rec.Scale = def.Recording.Scale })
+	effects := s.Graphics.PostEffects[:0]
+	for _, name := range s.Graphics.PostEffects {
+		if !slices.Contains(Effects, name) {
+			errs = append(errs, fmt.Errorf("unknown post effect %q, skipped", name))
+			continue
+		}
+		effects = append(effects, name)
+	}
+	s.Graphics.PostEffects = effects
+	return errors.Join(errs...)
+}
+
+func percent(v int) bool {
+	return v >= 0 && v <= 100
+}
@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadV0(t *testing.T) {
+	s, err := Load(filepath.Join("testdata", "v0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Window.Width, want.Window.Height = 1920, 1080
+	want.Window.Fullscreen = true
+	want.Window.FPS = 144
+	want.Audio.Master = 70
+	want.Controls = map[string]string{"left": "A", "right": "D", "shoot": "Space", "pause": "Escape"}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("loaded %+v\nwant %+v", s, want)
+	}
+
+	// Saved file is current and loads to the same settings
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := Save(path, s); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["version"] != float64(Version) || raw["width"] != nil {
+		t.Errorf("saved version %v, top level width %v", raw["version"], raw["width"])
+	}
+	again, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, s) {
+		t.Errorf("round trip %+v\nwant %+v", again, s)
+	}
+}
+
+func TestMigrateRejectsNewer(t *testing.T) {
+	if _, err := migrate([]byte(`{"version": 99}`)); err == nil {
+		t.Error("newer version accepted")
+	}
+	if _, err := migrate([]byte(`{"volume": "loud"}`)); err == nil {
+		t.Error("bad v0 volume accepted")
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	ValidKey = func(name string) bool { return name == "A" || name == "Space" }
+	defer func() { ValidKey = nil }()
+
+	s := Default()
+	s.Window.FPS = 1000
+	s.Audio.Music = -5
+	s.Controls["left"] = "A"
+	s.Controls["right"] = "NoSuchKey"
+	s.Controls["shoot"] = ""
+	s.Controls["jump"] = "Space"
+	s.Graphics.PostEffects = []string{EffectCRT, "blur"}
+	err := s.Sanitize()
+	if err == nil {
+		t.Fatal("no error")
+	}
+	for _, want := range []string{"fps", "music volume", "key for right", "key for shoot", `"jump"`, `"blur"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	def := Default()
+	if s.Window.FPS != def.Window.FPS || s.Audio.Music != def.Audio.Music {
+		t.Errorf("fps %d, music %d not reset", s.Window.FPS, s.Audio.Music)
+	}
+	wantControls := map[string]string{"left": "A", "right": "Right", "shoot": "Space", "pause": "Pause"}
+	if !reflect.DeepEqual(s.Controls, wantControls) {
+		t.Errorf("controls %v, want %v", s.Controls, wantControls)
+	}
+	if !reflect.DeepEqual(s.Graphics.PostEffects, []string{EffectCRT}) {
+		t.Errorf("effects %v", s.Graphics.PostEffects)
+	}
+
+	// Default keys pass the validator only if it knows them
+	if err := Default().Sanitize(); err == nil {
+		t.Error("default keys unknown to the validator accepted")
+	}
+	ValidKey = nil
+	if err := Default().Sanitize(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+}
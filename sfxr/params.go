@@ -0,0 +1,233 @@
+package sfxr
+
+import (
+	"math/rand"
+)
+
+// Wave is the base oscillator shape
+type Wave int
+
+const (
+	Square Wave = iota
+	Sawtooth
+	Sine
+	Noise
+)
+
+// Params describe a sound the way sfxr does, most values are in 0..1,
+// ramps and some offsets are in -1..1
+type Params struct {
+	Wave Wave
+
+	// Start frequency, lower limit and its slide
+	BaseFreq  float64
+	FreqLimit float64
+	FreqRamp  float64
+	FreqDRamp float64
+
+	// Square wave duty cycle and its slide
+	Duty     float64
+	DutyRamp float64
+
+	// Vibrato
+	VibStrength float64
+	VibSpeed    float64
+
+	// Volume envelope
+	EnvAttack  float64
+	EnvSustain float64
+	EnvDecay   float64
+	EnvPunch   float64
+
+	// Low pass filter
+	LPFResonance float64
+	LPFFreq      float64
+	LPFRamp      float64
+
+	// High pass filter
+	HPFFreq float64
+	HPFRamp float64
+
+	// Phaser
+	PhaOffset float64
+	PhaRamp   float64
+
+	// Restart period
+	RepeatSpeed float64
+
+	// Frequency jump
+	ArpSpeed float64
+	ArpMod   float64
+
+	// Output volume
+	Volume float64
+}
+
+// Default returns a plain short square beep
+func Default() Params {
+	return Params{
+		Wave:       Square,
+		BaseFreq:   0.3,
+		EnvSustain: 0.3,
+		EnvDecay:   0.4,
+		LPFFreq:    1,
+		Volume:     0.5,
+	}
+}
+
+// frnd returns random value in 0..max
+func frnd(rng *rand.Rand, max float64) float64 {
+	return rng.Float64() * max
+}
+
+// rnd returns random integer in 0..max inclusive
+func rnd(rng *rand.Rand, max int) int {
+	return rng.Intn(max + 1)
+}
+
+// Laser returns a random shot sound
+func Laser(rng *rand.Rand) Params {
+	p := Default()
+	p.Wave = Wave(rnd(rng, 2))
+	if p.Wave == Sine && rnd(rng, 1) == 1 {
+		p.Wave = Wave(rnd(rng, 1))
+	}
+	p.BaseFreq = 0.5 + frnd(rng, 0.5)
+	p.FreqLimit = max(p.BaseFreq-0.2-frnd(rng, 0.6), 0.2)
+	p.FreqRamp = -0.15 - frnd(rng, 0.2)
+	if rnd(rng, 2) == 0 {
+		p.BaseFreq = 0.3 + frnd(rng, 0.6)
+		p.FreqLimit = frnd(rng, 0.1)
+		p.FreqRamp = -0.35 - frnd(rng, 0.3)
+	}
+	if rnd(rng, 1) == 1 {
+		p.Duty = frnd(rng, 0.5)
+		p.DutyRamp = frnd(rng, 0.2)
+	} else {
+		p.Duty = 0.4 + frnd(rng, 0.5)
+		p.DutyRamp = -frnd(rng, 0.7)
+	}
+	p.EnvAttack = 0
+	p.EnvSustain = 0.1 + frnd(rng, 0.2)
+	p.EnvDecay = frnd(rng, 0.4)
+	if rnd(rng, 1) == 1 {
+		p.EnvPunch = frnd(rng, 0.3)
+	}
+	if rnd(rng, 2) == 0 {
+		p.PhaOffset = frnd(rng, 0.2)
+		p.PhaRamp = -frnd(rng, 0.2)
+	}
+	if rnd(rng, 1) == 1 {
+		p.HPFFreq = frnd(rng, 0.3)
+	}
+	return p
+}
+
+// Explosion returns a random noise blast
+func Explosion(rng *rand.Rand) Params {
+	p := Default()
+	p.Wave = Noise
+	if rnd(rng, 1) == 1 {
+		p.BaseFreq = 0.1 + frnd(rng, 0.4)
+		p.FreqRamp = -0.1 + frnd(rng, 0.4)
+	} else {
+		p.BaseFreq = 0.2 + frnd(rng, 0.7)
+		p.FreqRamp = -0.2 - frnd(rng, 0.2)
+	}
+	p.BaseFreq *= p.BaseFreq
+	if rnd(rng, 4) == 0 {
+		p.FreqRamp = 0
+	}
+	if rnd(rng, 2) == 0 {
+		p.RepeatSpeed = 0.3 + frnd(rng, 0.5)
+	}
+	p.EnvAttack = 0
+	p.EnvSustain = 0.1 + frnd(rng, 0.3)
+	p.EnvDecay = frnd(rng, 0.5)
+	if rnd(rng, 1) == 0 {
+		p.PhaOffset = -0.3 + frnd(rng, 0.9)
+		p.PhaRamp = -frnd(rng, 0.3)
+	}
+	p.EnvPunch = 0.2 + frnd(rng, 0.6)
+	if rnd(rng, 1) == 1 {
+		p.VibStrength = frnd(rng, 0.7)
+		p.VibSpeed = frnd(rng, 0.6)
+	}
+	if rnd(rng, 2) == 0 {
+		p.ArpSpeed = 0.6 + frnd(rng, 0.3)
+		p.ArpMod = 0.8 - frnd(rng, 1.6)
+	}
+	return p
+}
+
+// Pickup returns a random coin sound
+func Pickup(rng *rand.Rand) Params {
+	p := Default()
+	p.BaseFreq = 0.4 + frnd(rng, 0.5)
+	p.EnvAttack = 0
+	p.EnvSustain = frnd(rng, 0.1)
+	p.EnvDecay = 0.1 + frnd(rng, 0.4)
+	p.EnvPunch = 0.3 + frnd(rng, 0.3)
+	if rnd(rng, 1) == 1 {
+		p.ArpSpeed = 0.5 + frnd(rng, 0.2)
+		p.ArpMod = 0.2 + frnd(rng, 0.4)
+	}
+	return p
+}
+
+// Hit returns a random hurt sound
+func Hit(rng *rand.Rand) Params {
+	p := Default()
+	p.Wave = Wave(rnd(rng, 2))
+	if p.Wave == Sine {
+		p.Wave = Noise
+	}
+	if p.Wave == Square {
+		p.Duty = frnd(rng, 0.6)
+	}
+	p.BaseFreq = 0.2 + frnd(rng, 0.6)
+	p.FreqRamp = -0.3 - frnd(rng, 0.4)
+	p.EnvAttack = 0
+	p.EnvSustain = frnd(rng, 0.1)
+	p.EnvDecay = 0.1 + frnd(rng, 0.2)
+	if rnd(rng, 1) == 1 {
+		p.HPFFreq = frnd(rng, 0.3)
+	}
+	return p
+}
+
+// Preset builds sound of the kind from the seed, same seed gives same sound
+func Preset(kind func(*rand.Rand) Params, seed int64) Params {
+	return kind(rand.New(rand.NewSource(seed)))
+}
+
+// Mutate returns copy with about half of the values nudged by up to amount
+func (p Params) Mutate(rng *rand.Rand, amount float64) Params {
+	nudge := func(v *float64, lo, hi float64) {
+		if rnd(rng, 1) == 1 {
+			*v = min(max(*v+frnd(rng, amount*2)-amount, lo), hi)
+		}
+	}
+	nudge(&p.BaseFreq, 0, 1)
+	nudge(&p.FreqRamp, -1, 1)
+	nudge(&p.FreqDRamp, -1, 1)
+	nudge(&p.Duty, 0, 1)
+	nudge(&p.DutyRamp, -1, 1)
+	nudge(&p.VibStrength, 0, 1)
+	nudge(&p.VibSpeed, 0, 1)
+	nudge(&p.EnvAttack, 0, 1)
+	nudge(&p.EnvSustain, 0, 1)
+	nudge(&p.EnvDecay, 0, 1)
+	nudge(&p.EnvPunch, 0, 1)
+	nudge(&p.LPFResonance, 0, 1)
+	nudge(&p.LPFFreq, 0, 1)
+	nudge(&p.LPFRamp, -1, 1)
+	nudge(&p.HPFFreq, 0, 1)
+	nudge(&p.HPFRamp, -1, 1)
+	nudge(&p.PhaOffset, -1, 1)
+	nudge(&p.PhaRamp, -1, 1)
+	nudge(&p.RepeatSpeed, 0, 1)
+	nudge(&p.ArpSpeed, 0, 1)
+	nudge(&p.ArpMod, -1, 1)
+	return p
+}
@@ -0,0 +1,106 @@
+package sfxr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var presets = map[string]func(*rand.Rand) Params{
+	"laser":     Laser,
+	"explosion": Explosion,
+	"pickup":    Pickup,
+	"hit":       Hit,
+}
+
+func TestSynthesizeDeterministic(t *testing.T) {
+	for name, kind := range presets {
+		t.Run(name, func(t *testing.T) {
+			a := Preset(kind, 7).Synthesize(42)
+			b := Preset(kind, 7).Synthesize(42)
+			if len(a) == 0 {
+				t.Fatal("no samples")
+			}
+			if !slices.Equal(a, b) {
+				t.Error("same params and seed gave different samples")
+			}
+			if len(a) > MaxSamples {
+				t.Errorf("%d samples, limit %d", len(a), MaxSamples)
+			}
+			for i, v := range a {
+				if v < -1 || v > 1 {
+					t.Fatalf("sample %d = %f out of range", i, v)
+				}
+			}
+		})
+	}
+}
+
+func TestPresetSeeds(t *testing.T) {
+	if Preset(Laser, 1) != Preset(Laser, 1) {
+		t.Error("same seed gave different params")
+	}
+	if Preset(Laser, 1) == Preset(Laser, 2) {
+		t.Error("different seeds gave same params")
+	}
+}
+
+func TestWAV(t *testing.T) {
+	samples := []float64{0, 1, -1, 0.5, 2}
+	data := WAV(samples)
+	if len(data) != 44+len(samples)*2 {
+		t.Fatalf("length %d, want %d", len(data), 44+len(samples)*2)
+	}
+	le := binary.LittleEndian
+	for _, f := range []struct {
+		offset int
+		want   string
+	}{{0, "RIFF"}, {8, "WAVE"}, {12, "fmt "}, {36, "data"}} {
+		if got := string(data[f.offset : f.offset+4]); got != f.want {
+			t.Errorf("at %d %q, want %q", f.offset, got, f.want)
+		}
+	}
+	for _, f := range []struct {
+		name      string
+		got, want uint32
+	}{
+		{"riff size", le.Uint32(data[4:]), uint32(len(data) - 8)},
+		{"fmt size", le.Uint32(data[16:]), 16},
+		{"format", uint32(le.Uint16(data[20:])), 1},
+		{"channels", uint32(le.Uint16(data[22:])), 1},
+		{"sample rate", le.Uint32(data[24:]), SampleRate},
+		{"byte rate", le.Uint32(data[28:]), SampleRate * 2},
+		{"block align", uint32(le.Uint16(data[32:])), 2},
+		{"bits", uint32(le.Uint16(data[34:])), 16},
+		{"data size", le.Uint32(data[40:]), uint32(len(samples) * 2)},
+	} {
+		if f.got != f.want {
+			t.Errorf("%s = %d, want %d", f.name, f.got, f.want)
+		}
+	}
+	pcm := []int16{0, 32767, -32767, 16384, 32767}
+	for i, want := range pcm {
+		if got := int16(le.Uint16(data[44+i*2:])); got != want {
+			t.Errorf("sample %d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteWAVError(t *testing.T) {
+	if err := WriteWAV(failWriter{}, []float64{0}); err == nil {
+		t.Error("write error lost")
+	}
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, nil); err != nil || buf.Len() != 44 {
+		t.Errorf("empty wav: %v, %d bytes", err, buf.Len())
+	}
+}
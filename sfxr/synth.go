@@ -0,0 +1,261 @@
+package sfxr
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SampleRate of generated sound in Hz
+const SampleRate = 44100
+
+// MaxSamples limits sounds that never finish, five seconds
+const MaxSamples = SampleRate * 5
+
+// Levels used by sfxr to keep output away from clipping
+const (
+	masterVolume = 0.05
+	// sfxr applies the same gain when exporting to get usable loudness
+	outputGain  = 4
+	superSample = 8
+	phaserSize  = 1024
+	noiseSize   = 32
+)
+
+type synth struct {
+	p   Params
+	rng *rand.Rand
+
+	playing bool
+	phase   int
+
+	fperiod, fmaxperiod float64
+	fslide, fdslide     float64
+	period              int
+
+	squareDuty, squareSlide float64
+
+	envStage  int
+	envTime   int
+	envLength [3]int
+	envVol    float64
+
+	fphase, fdphase float64
+	iphase, ipp     int
+	phaser          [phaserSize]float64
+	noise           [noiseSize]float64
+
+	fltp, fltdp, fltw, fltwD, fltdmp float64
+	fltphp, flthp, flthpD            float64
+
+	vibPhase, vibSpeed, vibAmp float64
+
+	repTime, repLimit int
+	arpTime, arpLimit int
+	arpMod            float64
+}
+
+// reset prepares oscillator, restart keeps filters and envelope
+// running and is used by the repeat option
+func (s *synth) reset(restart bool) {
+	p := &s.p
+	s.fperiod = 100 / (p.BaseFreq*p.BaseFreq + 0.001)
+	s.period = int(s.fperiod)
+	s.fmaxperiod = 100 / (p.FreqLimit*p.FreqLimit + 0.001)
+	s.fslide = 1 - math.Pow(p.FreqRamp, 3)*0.01
+	s.fdslide = -math.Pow(p.FreqDRamp, 3) * 0.000001
+	s.squareDuty = 0.5 - p.Duty*0.5
+	s.squareSlide = -p.DutyRamp * 0.00005
+	if p.ArpMod >= 0 {
+		s.arpMod = 1 - p.ArpMod*p.ArpMod*0.9
+	} else {
+		s.arpMod = 1 + p.ArpMod*p.ArpMod*10
+	}
+	s.arpTime = 0
+	s.arpLimit = int((1-p.ArpSpeed)*(1-p.ArpSpeed)*20000 + 32)
+	if p.ArpSpeed == 1 {
+		s.arpLimit = 0
+	}
+	if restart {
+		return
+	}
+
+	s.phase = 0
+	s.fltp, s.fltdp = 0, 0
+	s.fltw = math.Pow(p.LPFFreq, 3) * 0.1
+	s.fltwD = 1 + p.LPFRamp*0.0001
+	s.fltdmp = min(5/(1+p.LPFResonance*p.LPFResonance*20)*(0.01+s.fltw), 0.8)
+	s.fltphp = 0
+	s.flthp = p.HPFFreq * p.HPFFreq * 0.1
+	s.flthpD = 1 + p.HPFRamp*0.0003
+
+	s.vibPhase = 0
+	s.vibSpeed = p.VibSpeed * p.VibSpeed * 0.01
+	s.vibAmp = p.VibStrength * 0.5
+
+	s.envVol = 0
+	s.envStage, s.envTime = 0, 0
+	s.envLength = [3]int{
+		int(p.EnvAttack * p.EnvAttack * 100000),
+		int(p.EnvSustain * p.EnvSustain * 100000),
+		int(p.EnvDecay * p.EnvDecay * 100000),
+	}
+
+	s.fphase = p.PhaOffset * p.PhaOffset * 1020
+	if p.PhaOffset < 0 {
+		s.fphase = -s.fphase
+	}
+	s.fdphase = p.PhaRamp * p.PhaRamp
+	if p.PhaRamp < 0 {
+		s.fdphase = -s.fdphase
+	}
+	s.iphase = int(math.Abs(s.fphase))
+	s.ipp = 0
+	s.phaser = [phaserSize]float64{}
+	s.fillNoise()
+
+	s.repTime = 0
+	s.repLimit = int((1-p.RepeatSpeed)*(1-p.RepeatSpeed)*20000 + 32)
+	if p.RepeatSpeed == 0 {
+		s.repLimit = 0
+	}
+}
+
+func (s *synth) fillNoise() {
+	for i := range s.noise {
+		s.noise[i] = s.rng.Float64()*2 - 1
+	}
+}
+
+// sample produces next output value in -1..1
+func (s *synth) sample() float64 {
+	p := &s.p
+
+	s.repTime++
+	if s.repLimit != 0 && s.repTime >= s.repLimit {
+		s.repTime = 0
+		s.reset(true)
+	}
+
+	// Frequency jump and slide
+	s.arpTime++
+	if s.arpLimit != 0 && s.arpTime >= s.arpLimit {
+		s.arpLimit = 0
+		s.fperiod *= s.arpMod
+	}
+	s.fslide += s.fdslide
+	s.fperiod *= s.fslide
+	if s.fperiod > s.fmaxperiod {
+		s.fperiod = s.fmaxperiod
+		if p.FreqLimit > 0 {
+			s.playing = false
+		}
+	}
+	rfperiod := s.fperiod
+	if s.vibAmp > 0 {
+		s.vibPhase += s.vibSpeed
+		rfperiod = s.fperiod * (1 + math.Sin(s.vibPhase)*s.vibAmp)
+	}
+	s.period = max(int(rfperiod), 8)
+	s.squareDuty = min(max(s.squareDuty+s.squareSlide, 0), 0.5)
+
+	// Volume envelope
+	s.envTime++
+	if s.envTime > s.envLength[s.envStage] {
+		s.envTime = 0
+		s.envStage++
+		if s.envStage == 3 {
+			s.playing = false
+			return 0
+		}
+	}
+	progress := 1.0
+	if l := s.envLength[s.envStage]; l > 0 {
+		progress = float64(s.envTime) / float64(l)
+	}
+	switch s.envStage {
+	case 0:
+		s.envVol = progress
+	case 1:
+		s.envVol = 1 + (1-progress)*2*p.EnvPunch
+	case 2:
+		s.envVol = 1 - progress
+	}
+
+	// Phaser step
+	s.fphase += s.fdphase
+	s.iphase = min(int(math.Abs(s.fphase)), phaserSize-1)
+
+	if s.flthpD != 0 {
+		s.flthp = min(max(s.flthp*s.flthpD, 0.00001), 0.1)
+	}
+
+	total := 0.0
+	for i := 0; i < superSample; i++ {
+		s.phase++
+		if s.phase >= s.period {
+			s.phase %= s.period
+			if p.Wave == Noise {
+				s.fillNoise()
+			}
+		}
+
+		fp := float64(s.phase) / float64(s.period)
+		var v float64
+		switch p.Wave {
+		case Square:
+			if fp < s.squareDuty {
+				v = 0.5
+			} else {
+				v = -0.5
+			}
+		case Sawtooth:
+			v = 1 - fp*2
+		case Sine:
+			v = math.Sin(fp * 2 * math.Pi)
+		case Noise:
+			v = s.noise[s.phase*noiseSize/s.period]
+		}
+
+		// Low pass filter
+		pp := s.fltp
+		s.fltw = min(max(s.fltw*s.fltwD, 0), 0.1)
+		if p.LPFFreq != 1 {
+			s.fltdp += (v - s.fltp) * s.fltw
+			s.fltdp -= s.fltdp * s.fltdmp
+		} else {
+			s.fltp = v
+			s.fltdp = 0
+		}
+		s.fltp += s.fltdp
+
+		// High pass filter
+		s.fltphp += s.fltp - pp
+		s.fltphp -= s.fltphp * s.flthp
+		v = s.fltphp
+
+		// Phaser
+		s.phaser[s.ipp&(phaserSize-1)] = v
+		v += s.phaser[(s.ipp-s.iphase+phaserSize)&(phaserSize-1)]
+		s.ipp = (s.ipp + 1) & (phaserSize - 1)
+
+		total += v * s.envVol
+	}
+	out := total / superSample * masterVolume * 2 * p.Volume * outputGain
+	return min(max(out, -1), 1)
+}
+
+// Synthesize renders the sound as mono samples at SampleRate,
+// noise is taken from the seed so same params and seed give same output
+func (p Params) Synthesize(seed int64) []float64 {
+	s := &synth{p: p, rng: rand.New(rand.NewSource(seed)), playing: true}
+	s.reset(false)
+	var out []float64
+	for s.playing && len(out) < MaxSamples {
+		v := s.sample()
+		if !s.playing {
+			break
+		}
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package sfxr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// PCM16 converts samples to signed 16 bit little endian PCM
+func PCM16(samples []float64) []byte {
+	out := make([]byte, len(samples)*2)
+	for i, v := range samples {
+		v = min(max(v, -1), 1)
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(math.Round(v*math.MaxInt16))))
+	}
+	return out
+}
+
+// WriteWAV writes mono 16 bit WAV file with samples at SampleRate
+func WriteWAV(w io.Writer, samples []float64) error {
+	data := PCM16(samples)
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		blockAlign    = channels * bitsPerSample / 8
+	)
+	header := []any{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + len(data)),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16),
+		uint16(1), // PCM
+		uint16(channels),
+		uint32(SampleRate),
+		uint32(SampleRate * blockAlign),
+		uint16(blockAlign),
+		uint16(bitsPerSample),
+		[4]byte{'d', 'a', 't', 'a'},
+		uint32(len(data)),
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// WAV returns samples as WAV file contents
+func WAV(samples []float64) []byte {
+	var buf bytes.Buffer
+	// Writes to bytes.Buffer never return an error, it panics when out of memory
+	_ = WriteWAV(&buf, samples)
+	return buf.Bytes()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sdl_learn/audio"
+	"sdl_learn/gobject"
+	"sdl_learn/logger"
+	"sdl_learn/settings"
+	"sdl_learn/sfxr"
+)
+
+// Directories with sound effects and music
+const (
+	SoundDir = "assets/sfx"
+	MusicDir = "assets/music"
+)
+
+// Enemies on screen giving full music intensity
+const fullIntensityEnemies = 4
+
+// Effects played on game object events
+var eventSounds = map[gobject.Event]audio.Sound{
+	gobject.EventShoot:     audio.Shoot,
+	gobject.EventHit:       audio.Hit,
+	gobject.EventExplosion: audio.Explosion,
+	gobject.EventPickup:    audio.Pickup,
+}
+
+// initSound opens audio and connects it to game object events,
+// the game keeps running silently when audio is unavailable
+func initSound() *audio.Manager {
+	sounds, err := audio.NewManager()
+	if err != nil {
+		logger.Error("unable to open audio: %s", err.Error())
+	}
+	for _, err := range sounds.LoadDefaults(SoundDir) {
+		// Missing files are replaced with generated effects
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Error("unable to load sound: %s", err.Error())
+		}
+	}
+	synthesizeMissing(sounds)
+	registerMusic(sounds.Music)
+	applyVolume(sounds)
+	sounds.SetField(WindowWidth, WindowHeight)
+	gobject.OnEvent = func(e gobject.Event, gob *gobject.Gobject) {
+		sounds.PlayAt(eventSounds[e], gob.X+gob.Width/2, gob.Y+gob.Height/2)
+	}
+	return sounds
+}
+
+// Generated effects used when sound files are missing,
+// fixed seeds keep them the same between runs
+var synthSounds = map[audio.Sound]struct {
+	preset func(*rand.Rand) sfxr.Params
+	seed   int64
+}{
+	audio.Shoot:     {sfxr.Laser, 7},
+	audio.Hit:       {sfxr.Hit, 3},
+	audio.Explosion: {sfxr.Explosion, 11},
+	audio.Pickup:    {sfxr.Pickup, 5},
+}
+
+// synthesizeMissing generates effects which have no files
+func synthesizeMissing(sounds *audio.Manager) {
+	if !sounds.Open() {
+		return
+	}
+	for sound, category := range audio.DefaultSounds {
+		synth, ok := synthSounds[sound]
+		if sounds.Loaded(sound) || !ok {
+			continue
+		}
+		samples := sfxr.Preset(synth.preset, synth.seed).Synthesize(synth.seed)
+		if err := sounds.LoadData(sound, sfxr.WAV(samples), category); err != nil {
+			logger.Error("unable to load generated sound %s: %s", string(sound), err.Error())
+		}
+	}
+}
+
+// registerMusic adds tracks played by scenes,
+// gameplay stems come in as more enemies are alive
+func registerMusic(music *audio.Music) {
+	file := func(name string) string {
+		return filepath.Join(MusicDir, name+".ogg")
+	}
+	music.Register(audio.MusicMenu, &audio.Track{
+		Loop: file("menu"),
+	})
+	music.Register(audio.MusicGameplay, &audio.Track{
+		Intro: file("gameplay_intro"),
+		Loop:  file("gameplay_loop"),
+		Layers: []audio.Layer{
+			{File: file("gameplay_drums"), Threshold: 0.25},
+			{File: file("gameplay_lead"), Threshold: 0.75},
+		},
+	})
+	music.Register(audio.MusicBoss, &audio.Track{
+		Intro: file("boss_intro"),
+		Loop:  file("boss_loop"),
+	})
+}
+
+// musicIntensity grows with the number of live enemies
+func musicIntensity(enemies int) float64 {
+	return float64(enemies) / fullIntensityEnemies
+}
+
+// applyVolume sets volumes from settings
+func applyVolume(sounds *audio.Manager) {
+	a := settings.Current.Audio
+	sounds.SetVolume(a.Master, a.Music, a.Effects)
+}
@@ -0,0 +1,62 @@
+package background
+
+import (
+	"sdl_learn/starfield"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Background draws star field through a streaming texture,
+// it lives apart from starfield so the simulation builds without SDL
+type Background struct {
+	Field   *starfield.Field
+	pixels  []byte
+	texture *sdl.Texture
+}
+
+// New creates texture of the field size, with nil renderer there is
+// no texture and the caller uploads pixels from Render itself
+func New(r *sdl.Renderer, f *starfield.Field) (*Background, error) {
+	b := &Background{
+		Field:  f,
+		pixels: make([]byte, f.Width*f.Height*4),
+	}
+	if r != nil {
+		var err error
+		b.texture, err = r.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STREAMING, int32(f.Width), int32(f.Height))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Update moves stars, elapsed is in frames of 60 FPS
+func (b *Background) Update(elapsed float64) {
+	b.Field.Update(elapsed)
+}
+
+// Render draws field into RGBA pixels, rows are Field.Width*4 bytes
+func (b *Background) Render() []byte {
+	b.Field.ClearAndDraw(b.pixels)
+	return b.pixels
+}
+
+// Draw renders field into dst, nil dst fills the whole target,
+// backgrounds made without renderer draw nothing
+func (b *Background) Draw(r *sdl.Renderer, dst *sdl.Rect) {
+	if b.texture == nil || r == nil {
+		return
+	}
+	b.texture.Update(nil, unsafe.Pointer(&b.Render()[0]), b.Field.Width*4)
+	r.Copy(b.texture, nil, dst)
+}
+
+// Free resources
+func (b *Background) Free() {
+	b.Field.Close()
+	if b.texture != nil {
+		b.texture.Destroy()
+	}
+}
@@ -0,0 +1,45 @@
+package starfield
+
+import "sync"
+
+// pool runs the same job on every worker and waits for all of them
+type pool struct {
+	jobs []chan func(worker int)
+	wg   sync.WaitGroup
+}
+
+func newPool(workers int) *pool {
+	p := &pool{jobs: make([]chan func(int), workers)}
+	for i := range p.jobs {
+		p.jobs[i] = make(chan func(int))
+		go p.work(i)
+	}
+	return p
+}
+
+func (p *pool) work(i int) {
+	for job := range p.jobs[i] {
+		job(i)
+		p.wg.Done()
+	}
+}
+
+// run calls job once per worker and waits until all are done
+func (p *pool) run(job func(worker int)) {
+	p.wg.Add(len(p.jobs))
+	for _, jobs := range p.jobs {
+		jobs <- job
+	}
+	p.wg.Wait()
+}
+
+func (p *pool) size() int {
+	return len(p.jobs)
+}
+
+// close stops workers
+func (p *pool) close() {
+	for _, jobs := range p.jobs {
+		close(jobs)
+	}
+}
@@ -0,0 +1,321 @@
+package starfield
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"runtime"
+	"sdl_learn/raster"
+	"time"
+)
+
+// Mode is the direction stars fly in
+type Mode int
+
+const (
+	// Radial stars fly out of the center, like flying forward
+	Radial Mode = iota
+	// Vertical stars scroll down, like flying up the screen
+	Vertical
+)
+
+// Config describes star field
+type Config struct {
+	// Size of the pixel buffer
+	Width, Height int
+	// Number of stars
+	Count int
+	// Depth layers, far layers are slower and dimmer
+	Layers int
+	// Velocity multiplier, 0.1 is a calm drift
+	Warp float64
+	Mode Mode
+	// Length of the fading trail behind a star in pixels, 0 draws dots
+	TrailLength int
+	// Seed of the random generator, 0 takes current time
+	Seed int64
+	// Goroutines updating and drawing stars, 0 uses all CPUs, 1 works
+	// on the calling goroutine
+	Workers int
+}
+
+// DefaultConfig returns the example1 field: 30000 radial stars
+func DefaultConfig(width, height int) Config {
+	return Config{
+		Width:  width,
+		Height: height,
+		Count:  30000,
+		Layers: 3,
+		Warp:   0.1,
+		Mode:   Radial,
+	}
+}
+
+// Star is a single point of the field
+type Star struct {
+	X, Y       float64
+	VX, VY     float64
+	Brightness byte
+	Layer      int
+}
+
+// Field simulates stars and draws them into RGBA pixel buffer
+type Field struct {
+	Config
+	Stars []Star
+	// Random generators of star chunks, each worker updates whole
+	// chunks so respawns don't depend on the worker count
+	rngs []*rand.Rand
+	pool *pool
+	// Star indexes by updating worker and drawing band
+	bins [][][]int32
+}
+
+// New creates field with all stars placed
+func New(cfg Config) *Field {
+	if cfg.Layers < 1 {
+		cfg.Layers = 1
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	cfg.Workers = max(min(cfg.Workers, cfg.Height), 1)
+	f := &Field{
+		Config: cfg,
+		Stars:  make([]Star, cfg.Count),
+		rngs:   make([]*rand.Rand, chunks),
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for i := range f.Stars {
+		f.Stars[i] = f.newStar(rng, true)
+	}
+	for i := range f.rngs {
+		f.rngs[i] = rand.New(rand.NewSource(seed + int64(i) + 1))
+	}
+	if cfg.Workers > 1 {
+		f.pool = newPool(cfg.Workers)
+		f.bins = make([][][]int32, cfg.Workers)
+		for i := range f.bins {
+			f.bins[i] = make([][]int32, cfg.Workers)
+		}
+		f.pool.run(func(worker int) {
+			from, to := f.workerStars(worker)
+			f.bin(worker, from, to)
+		})
+	}
+	return f
+}
+
+// Close stops worker goroutines
+func (f *Field) Close() {
+	if f.pool != nil {
+		f.pool.close()
+		f.pool = nil
+	}
+}
+
+func randFloat64(rng *rand.Rand, min, max float64) float64 {
+	return min + rng.Float64()*(max-min)
+}
+
+// layerScale returns speed and brightness factor of the layer, nearest is 1
+func (f *Field) layerScale(layer int) float64 {
+	return float64(layer+1) / float64(f.Layers)
+}
+
+// newStar creates star at its spawn point, scattered stars
+// start anywhere on the screen so the field is full from the first frame
+func (f *Field) newStar(rng *rand.Rand, scattered bool) Star {
+	layer := rng.Intn(f.Layers)
+	scale := f.layerScale(layer)
+	switch f.Mode {
+	case Vertical:
+		y := 0.0
+		if scattered {
+			y = randFloat64(rng, 0, float64(f.Height))
+		}
+		return Star{
+			X:          randFloat64(rng, 0, float64(f.Width)),
+			Y:          y,
+			VY:         randFloat64(rng, 10, 40) * scale,
+			Brightness: byte(255 * scale),
+			Layer:      layer,
+		}
+	default:
+		// Pick a direction and speed
+		angle := randFloat64(rng, -math.Pi, math.Pi)
+		speed := 255 * math.Pow(randFloat64(rng, 0.3, 1.0), 2) * scale
+		s := Star{
+			X:     float64(f.Width) / 2,
+			Y:     float64(f.Height) / 2,
+			VX:    speed * math.Cos(angle),
+			VY:    speed * math.Sin(angle),
+			Layer: layer,
+		}
+		if scattered {
+			d := randFloat64(rng, 0, float64(max(f.Width, f.Height))/2)
+			s.X += math.Cos(angle) * d
+			s.Y += math.Sin(angle) * d
+		}
+		return s
+	}
+}
+
+// Update moves stars, elapsed is in frames of 60 FPS
+// so 1 keeps the speed of the original example
+func (f *Field) Update(elapsed float64) {
+	if f.pool == nil {
+		f.updateChunks(0, chunks, elapsed)
+		return
+	}
+	f.pool.run(func(worker int) {
+		first, last := chunk(chunks, f.pool.size(), worker)
+		f.updateChunks(first, last, elapsed)
+		from, to := f.workerStars(worker)
+		f.bin(worker, from, to)
+	})
+}
+
+// chunks is the number of star chunks with their own random generator
+const chunks = 64
+
+// updateChunks moves stars of chunks [first, last)
+func (f *Field) updateChunks(first, last int, elapsed float64) {
+	for i := first; i < last; i++ {
+		from, to := chunk(len(f.Stars), chunks, i)
+		f.updateRange(f.rngs[i], from, to, elapsed)
+	}
+}
+
+// workerStars returns stars of the chunks updated by the worker
+func (f *Field) workerStars(worker int) (int, int) {
+	first, last := chunk(chunks, f.pool.size(), worker)
+	from, _ := chunk(len(f.Stars), chunks, first)
+	to, _ := chunk(len(f.Stars), chunks, last)
+	return from, to
+}
+
+// chunk returns bounds of the part i of n parts of length items
+func chunk(length, n, i int) (int, int) {
+	return length * i / n, length * (i + 1) / n
+}
+
+// band returns drawing band of the row
+func (f *Field) band(y int) int {
+	return min(max(y, 0), f.Height-1) * f.pool.size() / f.Height
+}
+
+// bin sorts stars in [from, to) by the bands their pixels touch,
+// star with trail crossing band border goes into every band it touches
+func (f *Field) bin(worker, from, to int) {
+	bins := f.bins[worker]
+	for b := range bins {
+		bins[b] = bins[b][:0]
+	}
+	for i := from; i < to; i++ {
+		y := int(f.Stars[i].Y)
+		first, last := f.band(y-f.TrailLength), f.band(y+f.TrailLength)
+		for b := first; b <= last; b++ {
+			bins[b] = append(bins[b], int32(i))
+		}
+	}
+}
+
+// updateRange moves stars in [from, to)
+func (f *Field) updateRange(rng *rand.Rand, from, to int, elapsed float64) {
+	step := f.Warp * elapsed
+	for i := from; i < to; i++ {
+		s := &f.Stars[i]
+		x := s.X + s.VX*step
+		y := s.Y + s.VY*step
+
+		// If we're off the screen with the new position reset else update position
+		if x >= float64(f.Width) || y >= float64(f.Height) || x < 0 || y < 0 {
+			*s = f.newStar(rng, false)
+			continue
+		}
+		s.X, s.Y = x, y
+		// Radial stars grow brighter as they come closer
+		if f.Mode == Radial {
+			limit := byte(255 * f.layerScale(s.Layer))
+			s.Brightness = byte(min(int(s.Brightness)+40, int(limit)))
+		}
+	}
+}
+
+// Draw plots stars into RGBA buffer of Width x Height,
+// with workers every worker owns a horizontal band of rows
+// so no pixel is written by two goroutines
+func (f *Field) Draw(pixels []byte) {
+	if f.pool == nil {
+		c := f.canvas(pixels)
+		for i := range f.Stars {
+			f.drawStar(&f.Stars[i], c)
+		}
+		return
+	}
+	f.pool.run(func(band int) {
+		c := f.bandCanvas(pixels, band)
+		for _, bins := range f.bins {
+			for _, i := range bins[band] {
+				f.drawStar(&f.Stars[i], c)
+			}
+		}
+	})
+}
+
+// ClearAndDraw clears buffer and draws stars in one pass over the workers
+func (f *Field) ClearAndDraw(pixels []byte) {
+	if f.pool == nil {
+		f.canvas(pixels).Clear(black)
+		f.Draw(pixels)
+		return
+	}
+	f.pool.run(func(band int) {
+		f.bandCanvas(pixels, band).Clear(black)
+	})
+	f.Draw(pixels)
+}
+
+var black = color.NRGBA{A: 255}
+
+// canvas wraps buffer, stars overwrite pixels
+func (f *Field) canvas(pixels []byte) *raster.Canvas {
+	c := raster.Wrap(pixels, f.Width, f.Height)
+	c.Mode = raster.Replace
+	return c
+}
+
+// bandCanvas wraps buffer clipped to the rows of the band
+func (f *Field) bandCanvas(pixels []byte, band int) *raster.Canvas {
+	c := f.canvas(pixels)
+	top, bottom := chunk(f.Height, f.pool.size(), band)
+	c.SetClip(image.Rect(0, top, f.Width, bottom))
+	return c
+}
+
+// drawStar plots star with its trail, trail fades out behind the star
+func (f *Field) drawStar(s *Star, c *raster.Canvas) {
+	c.Set(int(s.X), int(s.Y), grey(s.Brightness))
+	if f.TrailLength <= 0 {
+		return
+	}
+	speed := math.Hypot(s.VX, s.VY)
+	if speed == 0 {
+		return
+	}
+	dx, dy := s.VX/speed, s.VY/speed
+	for t := 1; t <= f.TrailLength; t++ {
+		v := int(s.Brightness) * (f.TrailLength - t + 1) / (f.TrailLength + 1)
+		c.Set(int(s.X-dx*float64(t)), int(s.Y-dy*float64(t)), grey(byte(v)))
+	}
+}
+
+func grey(v byte) color.NRGBA {
+	return color.NRGBA{R: v, G: v, B: v, A: 255}
+}
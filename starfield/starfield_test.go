@@ -0,0 +1,99 @@
+package starfield
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+func TestSameSeedSameFrame(t *testing.T) {
+	frame := func() []byte {
+		cfg := DefaultConfig(320, 200)
+		cfg.Count = 2000
+		cfg.Seed = 1
+		cfg.Workers = 1
+		cfg.TrailLength = 3
+		f := New(cfg)
+		defer f.Close()
+		pixels := make([]byte, cfg.Width*cfg.Height*4)
+		for i := 0; i < 10; i++ {
+			f.Update(1)
+		}
+		f.ClearAndDraw(pixels)
+		return pixels
+	}
+	a, b := frame(), frame()
+	if !bytes.Equal(a, b) {
+		t.Error("same seed drew different frames")
+	}
+	if bytes.Equal(a, make([]byte, len(a))) {
+		t.Error("no stars drawn")
+	}
+}
+
+func TestWorkersSameFrame(t *testing.T) {
+	for _, mode := range []Mode{Radial, Vertical} {
+		frames := make(map[int][]byte)
+		for _, workers := range []int{1, 4} {
+			cfg := DefaultConfig(320, 200)
+			cfg.Count = 2000
+			cfg.Seed = 1
+			cfg.Mode = mode
+			cfg.Workers = workers
+			cfg.TrailLength = 3
+			f := New(cfg)
+			pixels := make([]byte, cfg.Width*cfg.Height*4)
+			// Long enough for many stars to leave and respawn
+			for i := 0; i < 30; i++ {
+				f.Update(1)
+				f.ClearAndDraw(pixels)
+			}
+			f.Close()
+			frames[workers] = pixels
+		}
+		if !bytes.Equal(frames[1], frames[4]) {
+			t.Errorf("mode %d drew different frames with 1 and 4 workers", mode)
+		}
+	}
+}
+
+// BenchmarkStarfield measures one frame, update plus draw, e.g.
+// go test ./starfield -bench . -benchtime 100x
+func BenchmarkStarfield(b *testing.B) {
+	workers := []int{1, 2, 4}
+	if n := runtime.NumCPU(); n > 4 {
+		workers = append(workers, n)
+	}
+	for _, mode := range []struct {
+		name  string
+		mode  Mode
+		trail int
+	}{
+		{"radial", Radial, 0},
+		{"vertical", Vertical, 0},
+		{"vertical-trail", Vertical, 3},
+	} {
+		for _, count := range []int{10000, 100000} {
+			for _, w := range workers {
+				name := fmt.Sprintf("%s/stars=%d/workers=%d", mode.name, count, w)
+				b.Run(name, func(b *testing.B) {
+					cfg := DefaultConfig(1280, 720)
+					cfg.Count = count
+					cfg.Mode = mode.mode
+					cfg.TrailLength = mode.trail
+					cfg.Workers = w
+					cfg.Seed = 1
+					f := New(cfg)
+					defer f.Close()
+					pixels := make([]byte, cfg.Width*cfg.Height*4)
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						f.Update(1)
+						f.ClearAndDraw(pixels)
+					}
+				})
+			}
+		}
+	}
+}
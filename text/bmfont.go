@@ -0,0 +1,306 @@
+package text
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Glyph describes one character of the bitmap font
+type Glyph struct {
+	ID               rune
+	X, Y, W, H       int32
+	XOffset, YOffset int32
+	XAdvance         int32
+	Page             int
+}
+
+type kerningPair struct {
+	first, second rune
+}
+
+// MaxPages limits page ids of fonts which don't tell their page count
+const MaxPages = 64
+
+// BMFontInfo is a parsed AngelCode BMFont text descriptor
+type BMFontInfo struct {
+	Face       string
+	LineHeight int32
+	Base       int32
+	Pages      []string
+	Glyphs     map[rune]Glyph
+	Kerning    map[kerningPair]int32
+}
+
+// Kern returns horizontal adjustment between two characters
+func (info *BMFontInfo) Kern(first, second rune) int32 {
+	return info.Kerning[kerningPair{first, second}]
+}
+
+// ParseBMFont reads BMFont descriptor in text format
+func ParseBMFont(reader io.Reader) (*BMFontInfo, error) {
+	info := &BMFontInfo{
+		Glyphs:  make(map[rune]Glyph),
+		Kerning: make(map[kerningPair]int32),
+	}
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	pages := int32(MaxPages)
+	for scanner.Scan() {
+		line++
+		tag, attrs := parseBMFontLine(scanner.Text())
+		var err error
+		switch tag {
+		case "info":
+			info.Face = attrs["face"]
+		case "common":
+			info.LineHeight, err = attrInt(attrs, "lineHeight")
+			if err == nil {
+				info.Base, err = attrInt(attrs, "base")
+			}
+			if _, ok := attrs["pages"]; ok && err == nil {
+				pages, err = attrInt(attrs, "pages")
+				if err == nil && (pages < 1 || pages > MaxPages) {
+					err = fmt.Errorf("bad pages %d", pages)
+				}
+			}
+		case "page":
+			var id int32
+			id, err = attrInt(attrs, "id")
+			if err == nil && (id < 0 || id >= pages) {
+				err = fmt.Errorf("bad page id %d", id)
+			}
+			if err == nil {
+				for int(id) >= len(info.Pages) {
+					info.Pages = append(info.Pages, "")
+				}
+				info.Pages[id] = attrs["file"]
+			}
+		case "char":
+			var g Glyph
+			g, err = parseGlyph(attrs)
+			if err == nil && (g.Page < 0 || g.Page >= int(pages)) {
+				err = fmt.Errorf("bad page %d of char %d", g.Page, g.ID)
+			}
+			if err == nil {
+				info.Glyphs[g.ID] = g
+			}
+		case "kerning":
+			var first, second, amount int32
+			if first, err = attrInt(attrs, "first"); err != nil {
+				break
+			}
+			if second, err = attrInt(attrs, "second"); err != nil {
+				break
+			}
+			if amount, err = attrInt(attrs, "amount"); err != nil {
+				break
+			}
+			info.Kerning[kerningPair{rune(first), rune(second)}] = amount
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bmfont: line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(info.Pages) == 0 {
+		return nil, fmt.Errorf("bmfont: no pages")
+	}
+	// Without the pages count glyphs are checked against listed pages
+	for _, g := range info.Glyphs {
+		if g.Page >= len(info.Pages) {
+			return nil, fmt.Errorf("bmfont: char %d on missing page %d", g.ID, g.Page)
+		}
+	}
+	return info, nil
+}
+
+func parseGlyph(attrs map[string]string) (Glyph, error) {
+	var g Glyph
+	fields := []struct {
+		name string
+		dst  *int32
+	}{
+		{"x", &g.X}, {"y", &g.Y}, {"width", &g.W}, {"height", &g.H},
+		{"xoffset", &g.XOffset}, {"yoffset", &g.YOffset}, {"xadvance", &g.XAdvance},
+	}
+	id, err := attrInt(attrs, "id")
+	if err != nil {
+		return g, err
+	}
+	g.ID = rune(id)
+	for _, f := range fields {
+		if *f.dst, err = attrInt(attrs, f.name); err != nil {
+			return g, err
+		}
+	}
+	if _, ok := attrs["page"]; ok {
+		page, err := attrInt(attrs, "page")
+		if err != nil {
+			return g, err
+		}
+		g.Page = int(page)
+	}
+	return g, nil
+}
+
+// parseBMFontLine splits line into tag and key=value pairs,
+// values may be quoted and contain spaces
+func parseBMFontLine(line string) (string, map[string]string) {
+	line = strings.TrimSpace(line)
+	tag, rest, _ := strings.Cut(line, " ")
+	attrs := make(map[string]string)
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				attrs[key] = value[1:]
+				break
+			}
+			attrs[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, " ")
+			attrs[key] = value
+		}
+	}
+	return tag, attrs
+}
+
+func attrInt(attrs map[string]string, key string) (int32, error) {
+	value, ok := attrs[key]
+	if !ok {
+		return 0, fmt.Errorf("missing %s", key)
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad %s: %w", key, err)
+	}
+	return int32(n), nil
+}
+
+// Bitmap is a font drawn from glyph sheet textures
+type Bitmap struct {
+	Info  *BMFontInfo
+	pages []*sdl.Texture
+}
+
+// NewBitmap loads BMFont descriptor and its pages,
+// page files are looked up next to the descriptor
+func NewBitmap(r *sdl.Renderer, file string) (*Bitmap, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := ParseBMFont(f)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bitmap{Info: info}
+	dir := filepath.Dir(file)
+	for _, page := range info.Pages {
+		texture, err := img.LoadTexture(r, filepath.Join(dir, page))
+		if err != nil {
+			b.Free()
+			return nil, err
+		}
+		texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+		b.pages = append(b.pages, texture)
+	}
+	return b, nil
+}
+
+// Draw renders string with its top left corner at x, y,
+// glyphs are tinted by the color
+func (b *Bitmap) Draw(r *sdl.Renderer, s string, x, y int32, color sdl.Color) error {
+	for _, page := range b.pages {
+		page.SetColorMod(color.R, color.G, color.B)
+		page.SetAlphaMod(color.A)
+	}
+	startX := x
+	var prev rune
+	for _, ch := range s {
+		if ch == '\n' {
+			x = startX
+			y += b.Info.LineHeight
+			prev = 0
+			continue
+		}
+		g, ok := b.Info.Glyphs[ch]
+		if !ok {
+			prev = 0
+			continue
+		}
+		x += b.Info.Kern(prev, ch)
+		if g.W > 0 && g.H > 0 && g.Page < len(b.pages) {
+			src := sdl.Rect{X: g.X, Y: g.Y, W: g.W, H: g.H}
+			dst := sdl.Rect{X: x + g.XOffset, Y: y + g.YOffset, W: g.W, H: g.H}
+			if err := r.Copy(b.pages[g.Page], &src, &dst); err != nil {
+				return err
+			}
+		}
+		x += g.XAdvance
+		prev = ch
+	}
+	return nil
+}
+
+// Measure returns size of the rendered string in pixels
+func (b *Bitmap) Measure(s string) (int32, int32) {
+	if s == "" {
+		return 0, 0
+	}
+	var w, lineW int32
+	lines := int32(1)
+	var prev rune
+	for _, ch := range s {
+		if ch == '\n' {
+			lines++
+			lineW = 0
+			prev = 0
+			continue
+		}
+		g, ok := b.Info.Glyphs[ch]
+		if !ok {
+			prev = 0
+			continue
+		}
+		lineW += b.Info.Kern(prev, ch) + g.XAdvance
+		if lineW > w {
+			w = lineW
+		}
+		prev = ch
+	}
+	return w, lines * b.Info.LineHeight
+}
+
+// LineHeight returns distance between two lines
+func (b *Bitmap) LineHeight() int32 {
+	return b.Info.LineHeight
+}
+
+// Free resources
+func (b *Bitmap) Free() {
+	for _, page := range b.pages {
+		page.Destroy()
+	}
+	b.pages = nil
+}
@@ -0,0 +1,122 @@
+package text
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFont = `info face="Test Font" size=16
+common lineHeight=18 base=14 scaleW=64 scaleH=64 pages=2
+page id=0 file="font_0.png"
+page id=1 file="font_1.png"
+chars count=2
+char id=65 x=1 y=2 width=8 height=10 xoffset=0 yoffset=4 xadvance=9 page=0
+char id=86 x=10 y=2 width=8 height=10 xoffset=-1 yoffset=4 xadvance=8 page=1
+kernings count=1
+kerning first=65 second=86 amount=-2
+`
+
+func TestParseBMFont(t *testing.T) {
+	info, err := ParseBMFont(strings.NewReader(testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Face != "Test Font" || info.LineHeight != 18 || info.Base != 14 {
+		t.Errorf("common = %q %d %d", info.Face, info.LineHeight, info.Base)
+	}
+	if len(info.Pages) != 2 || info.Pages[0] != "font_0.png" || info.Pages[1] != "font_1.png" {
+		t.Errorf("pages = %q", info.Pages)
+	}
+	want := Glyph{ID: 'V', X: 10, Y: 2, W: 8, H: 10, XOffset: -1, YOffset: 4, XAdvance: 8, Page: 1}
+	if g := info.Glyphs['V']; g != want {
+		t.Errorf("glyph V = %+v, want %+v", g, want)
+	}
+	if k := info.Kern('A', 'V'); k != -2 {
+		t.Errorf("kern AV = %d, want -2", k)
+	}
+	if k := info.Kern('V', 'A'); k != 0 {
+		t.Errorf("kern VA = %d, want 0", k)
+	}
+}
+
+func TestParseBMFontErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, font string
+	}{
+		{"no pages", "common lineHeight=18 base=14\n"},
+		{"negative page id", "page id=-1 file=\"a.png\"\n"},
+		{"page id over count", "common lineHeight=18 base=14 pages=1\npage id=1 file=\"a.png\"\n"},
+		{"huge page id", "page id=2000000000 file=\"a.png\"\n"},
+		{"bad page count", "common lineHeight=18 base=14 pages=100000\n"},
+		{"missing line height", "common base=14\npage id=0 file=\"a.png\"\n"},
+		{"bad char", "page id=0 file=\"a.png\"\nchar id=65 x=one\n"},
+		{"bad kerning", "page id=0 file=\"a.png\"\nkerning first=65 second=66\n"},
+		{"negative char page", "common lineHeight=18 base=14 pages=1\npage id=0 file=\"a.png\"\nchar id=65 x=0 y=0 width=1 height=1 xoffset=0 yoffset=0 xadvance=1 page=-1\n"},
+		{"char page over count", "common lineHeight=18 base=14 pages=1\npage id=0 file=\"a.png\"\nchar id=65 x=0 y=0 width=1 height=1 xoffset=0 yoffset=0 xadvance=1 page=1\n"},
+		{"char on unlisted page", "page id=0 file=\"a.png\"\nchar id=65 x=0 y=0 width=1 height=1 xoffset=0 yoffset=0 xadvance=1 page=3\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseBMFont(strings.NewReader(tc.font)); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestParseBMFontLine(t *testing.T) {
+	tag, attrs := parseBMFontLine(`info face="Some Font" size=16 bold=0`)
+	if tag != "info" || attrs["face"] != "Some Font" || attrs["size"] != "16" || attrs["bold"] != "0" {
+		t.Errorf("got %q %q", tag, attrs)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	info, err := ParseBMFont(strings.NewReader(testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bitmap{Info: info}
+	for _, tc := range []struct {
+		s    string
+		w, h int32
+	}{
+		{"", 0, 0},
+		{"A", 9, 18},
+		// AV pair is kerned, VA is not
+		{"AV", 15, 18},
+		{"VA", 17, 18},
+		// Unknown characters are skipped and break kerning
+		{"A?V", 17, 18},
+		{"AVA\nA", 24, 36},
+	} {
+		if w, h := b.Measure(tc.s); w != tc.w || h != tc.h {
+			t.Errorf("measure %q = %d,%d want %d,%d", tc.s, w, h, tc.w, tc.h)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	info, err := ParseBMFont(strings.NewReader(testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bitmap{Info: info}
+	// Space has no glyph, so words are only as wide as their letters
+	for _, tc := range []struct {
+		s     string
+		width int32
+		want  []string
+	}{
+		{"AV AV", 30, []string{"AV AV"}},
+		{"AV AV", 20, []string{"AV", "AV"}},
+		{"AV\n\nA", 100, []string{"AV", "", "A"}},
+		// Long word is broken between characters, at least one per line
+		{"AAAA", 20, []string{"AA", "AA"}},
+		{"AV", 5, []string{"A", "V"}},
+	} {
+		if got := Wrap(b, tc.s, tc.width); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("wrap %q to %d = %q want %q", tc.s, tc.width, got, tc.want)
+		}
+	}
+}
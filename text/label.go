@@ -0,0 +1,95 @@
+package text
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TextureFont can render a whole string into a texture once
+type TextureFont interface {
+	Font
+	Texture(r *sdl.Renderer, s string, color sdl.Color) (*sdl.Texture, error)
+}
+
+// Label is a line of text that keeps its rendered texture
+// and renders it again only when text or color changes
+type Label struct {
+	Font    Font
+	Color   sdl.Color
+	text    string
+	texture *sdl.Texture
+	w, h    int32
+	dirty   bool
+}
+
+// NewLabel creates empty label
+func NewLabel(f Font, color sdl.Color) *Label {
+	return &Label{
+		Font:  f,
+		Color: color,
+	}
+}
+
+// SetText changes label text
+func (l *Label) SetText(s string) {
+	if s == l.text {
+		return
+	}
+	l.text = s
+	l.dirty = true
+}
+
+// SetColor changes label color
+func (l *Label) SetColor(color sdl.Color) {
+	if color == l.Color {
+		return
+	}
+	l.Color = color
+	l.dirty = true
+}
+
+// Text returns current label text
+func (l *Label) Text() string {
+	return l.text
+}
+
+// Size returns size of the label in pixels
+func (l *Label) Size() (int32, int32) {
+	if l.dirty || l.texture == nil {
+		return l.Font.Measure(l.text)
+	}
+	return l.w, l.h
+}
+
+// Draw renders label with its top left corner at x, y
+func (l *Label) Draw(r *sdl.Renderer, x, y int32) error {
+	tf, ok := l.Font.(TextureFont)
+	if !ok {
+		return l.Font.Draw(r, l.text, x, y, l.Color)
+	}
+	if l.dirty || l.texture == nil {
+		l.Free()
+		texture, err := tf.Texture(r, l.text, l.Color)
+		if err != nil {
+			return err
+		}
+		l.texture = texture
+		l.dirty = false
+		if texture == nil {
+			return nil
+		}
+		_, _, l.w, l.h, _ = texture.Query()
+	}
+	if l.texture == nil {
+		return nil
+	}
+	return r.Copy(l.texture, nil, &sdl.Rect{X: x, Y: y, W: l.w, H: l.h})
+}
+
+// Free resources
+func (l *Label) Free() {
+	if l.texture != nil {
+		l.texture.Destroy()
+		l.texture = nil
+	}
+	l.w, l.h = 0, 0
+}
@@ -0,0 +1,108 @@
+package text
+
+import (
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Align is horizontal text alignment relative to the anchor point
+type Align int
+
+const (
+	Left Align = iota
+	Center
+	Right
+)
+
+// Font draws strings on the renderer
+type Font interface {
+	// Draw renders string with its top left corner at x, y
+	Draw(r *sdl.Renderer, s string, x, y int32, color sdl.Color) error
+	// Measure returns size of the rendered string in pixels
+	Measure(s string) (w, h int32)
+	// LineHeight returns distance between two lines
+	LineHeight() int32
+}
+
+// DrawAligned renders string aligned around x
+func DrawAligned(r *sdl.Renderer, f Font, s string, x, y int32, align Align, color sdl.Color) error {
+	w, _ := f.Measure(s)
+	switch align {
+	case Center:
+		x -= w / 2
+	case Right:
+		x -= w
+	}
+	return f.Draw(r, s, x, y, color)
+}
+
+// Wrap splits string into lines not wider than width,
+// words longer than the width are broken between characters
+func Wrap(f Font, s string, width int32) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if w, _ := f.Measure(candidate); w <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = word
+			for {
+				if w, _ := f.Measure(line); w <= width {
+					break
+				}
+				head, tail := breakWord(f, line, width)
+				if tail == "" {
+					// Single character wider than the width keeps its line
+					break
+				}
+				lines = append(lines, head)
+				line = tail
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// breakWord returns the longest prefix fitting the width and the rest,
+// prefix always has at least one character
+func breakWord(f Font, word string, width int32) (string, string) {
+	runes := []rune(word)
+	n := 1
+	for n < len(runes) {
+		if w, _ := f.Measure(string(runes[:n+1])); w > width {
+			break
+		}
+		n++
+	}
+	return string(runes[:n]), string(runes[n:])
+}
+
+// DrawWrapped renders string wrapped to width, each line is aligned
+// inside the box starting at x, returns height of the drawn text
+func DrawWrapped(r *sdl.Renderer, f Font, s string, x, y, width int32, align Align, color sdl.Color) (int32, error) {
+	switch align {
+	case Center:
+		x += width / 2
+	case Right:
+		x += width
+	}
+	var h int32
+	for _, line := range Wrap(f, s, width) {
+		if err := DrawAligned(r, f, line, x, y+h, align, color); err != nil {
+			return h, err
+		}
+		h += f.LineHeight()
+	}
+	return h, nil
+}
@@ -0,0 +1,67 @@
+//go:build !nottf
+
+package text
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// TTF is a font rendered by SDL_ttf
+type TTF struct {
+	font *ttf.Font
+}
+
+// NewTTF opens TrueType font of the given point size,
+// ttf.Init must be called before
+func NewTTF(file string, size int) (*TTF, error) {
+	font, err := ttf.OpenFont(file, size)
+	if err != nil {
+		return nil, err
+	}
+	return &TTF{font: font}, nil
+}
+
+// Draw renders string with its top left corner at x, y
+func (f *TTF) Draw(r *sdl.Renderer, s string, x, y int32, color sdl.Color) error {
+	texture, err := f.Texture(r, s, color)
+	if err != nil || texture == nil {
+		return err
+	}
+	defer texture.Destroy()
+	_, _, w, h, _ := texture.Query()
+	return r.Copy(texture, nil, &sdl.Rect{X: x, Y: y, W: w, H: h})
+}
+
+// Texture renders string into a new texture owned by the caller,
+// empty string gives nil texture
+func (f *TTF) Texture(r *sdl.Renderer, s string, color sdl.Color) (*sdl.Texture, error) {
+	if s == "" {
+		return nil, nil
+	}
+	surface, err := f.font.RenderUTF8Blended(s, color)
+	if err != nil {
+		return nil, err
+	}
+	defer surface.Free()
+	return r.CreateTextureFromSurface(surface)
+}
+
+// Measure returns size of the rendered string in pixels
+func (f *TTF) Measure(s string) (int32, int32) {
+	w, h, err := f.font.SizeUTF8(s)
+	if err != nil {
+		return 0, 0
+	}
+	return int32(w), int32(h)
+}
+
+// LineHeight returns distance between two lines
+func (f *TTF) LineHeight() int32 {
+	return int32(f.font.LineSkip())
+}
+
+// Free resources
+func (f *TTF) Free() {
+	f.font.Close()
+}
@@ -0,0 +1,75 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxLayerTiles limits layer size, tiles are allocated before
+// compressed data is read
+const MaxLayerTiles = 1 << 24
+
+// checkSize rejects negative or huge layer sizes
+func checkSize(width, height int) error {
+	if width < 0 || height < 0 || (height > 0 && width > MaxLayerTiles/height) {
+		return fmt.Errorf("tilemap: bad layer size %dx%d", width, height)
+	}
+	return nil
+}
+
+// decodeBase64 unpacks base64 layer data, optionally compressed,
+// into little endian tiles
+func decodeBase64(s, compression string, count int) ([]Tile, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("tilemap: layer data: %w", err)
+	}
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("tilemap: layer data: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("tilemap: layer data: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	default:
+		return nil, fmt.Errorf("tilemap: unsupported compression %q", compression)
+	}
+	tiles := make([]Tile, count)
+	if err := binary.Read(r, binary.LittleEndian, tiles); err != nil {
+		return nil, fmt.Errorf("tilemap: layer data: %w", err)
+	}
+	return tiles, nil
+}
+
+// decodeCSV parses comma separated tiles
+func decodeCSV(s string) ([]Tile, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	tiles := make([]Tile, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("tilemap: layer data: %w", err)
+		}
+		tiles[i] = Tile(v)
+	}
+	return tiles, nil
+}
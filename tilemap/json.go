@@ -0,0 +1,279 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+type jsonProperty struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type jsonTile struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Class       string         `json:"class"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Properties  []jsonProperty `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID    int    `json:"firstgid"`
+	Source      string `json:"source"`
+	Name        string `json:"name"`
+	TileWidth   int    `json:"tilewidth"`
+	TileHeight  int    `json:"tileheight"`
+	Spacing     int    `json:"spacing"`
+	Margin      int    `json:"margin"`
+	Columns     int    `json:"columns"`
+	TileCount   int    `json:"tilecount"`
+	Image       string `json:"image"`
+	ImageWidth  int    `json:"imagewidth"`
+	ImageHeight int    `json:"imageheight"`
+	TileOffset  struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"tileoffset"`
+	Properties []jsonProperty `json:"properties"`
+	Tiles      []jsonTile     `json:"tiles"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float64        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Point      bool           `json:"point"`
+	Ellipse    bool           `json:"ellipse"`
+	Visible    *bool          `json:"visible"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Properties  []jsonProperty  `json:"properties"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+}
+
+type jsonMap struct {
+	Width           int            `json:"width"`
+	Height          int            `json:"height"`
+	TileWidth       int            `json:"tilewidth"`
+	TileHeight      int            `json:"tileheight"`
+	Orientation     string         `json:"orientation"`
+	Infinite        bool           `json:"infinite"`
+	BackgroundColor string         `json:"backgroundcolor"`
+	Properties      []jsonProperty `json:"properties"`
+	Tilesets        []jsonTileset  `json:"tilesets"`
+	Layers          []jsonLayer    `json:"layers"`
+}
+
+// ParseJSON reads map in Tiled JSON format, dir is used to find
+// external tilesets and images
+func ParseJSON(data []byte, dir string) (*Map, error) {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return nil, err
+	}
+	if jm.Infinite {
+		return nil, ErrInfinite
+	}
+	m := &Map{
+		Width:           jm.Width,
+		Height:          jm.Height,
+		TileWidth:       jm.TileWidth,
+		TileHeight:      jm.TileHeight,
+		Orientation:     jm.Orientation,
+		BackgroundColor: jm.BackgroundColor,
+		Properties:      jsonProperties(jm.Properties),
+	}
+	for _, jt := range jm.Tilesets {
+		var ts *Tileset
+		if jt.Source != "" {
+			var err error
+			ts, err = loadTileset(filepath.Join(dir, jt.Source))
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ts = jt.tileset(dir)
+		}
+		ts.FirstGID = jt.FirstGID
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	if err := m.addJSONLayers(jm.Layers, 0, 0, true); err != nil {
+		return nil, err
+	}
+	return m, m.finish()
+}
+
+// parseJSONTileset reads external tileset in Tiled JSON format
+func parseJSONTileset(data []byte, dir string) (*Tileset, error) {
+	var jt jsonTileset
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return nil, err
+	}
+	return jt.tileset(dir), nil
+}
+
+// addJSONLayers flattens layers, group offset and visibility are
+// passed to the children
+func (m *Map) addJSONLayers(layers []jsonLayer, offsetX, offsetY float64, visible bool) error {
+	for _, jl := range layers {
+		lv := visible && (jl.Visible == nil || *jl.Visible)
+		ox, oy := offsetX+jl.OffsetX, offsetY+jl.OffsetY
+		switch jl.Type {
+		case "tilelayer":
+			tiles, err := jl.tiles()
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", jl.Name, err)
+			}
+			opacity := 1.0
+			if jl.Opacity != nil {
+				opacity = *jl.Opacity
+			}
+			m.Layers = append(m.Layers, &TileLayer{
+				Name:       jl.Name,
+				Width:      jl.Width,
+				Height:     jl.Height,
+				Visible:    lv,
+				Opacity:    opacity,
+				OffsetX:    ox,
+				OffsetY:    oy,
+				Properties: jsonProperties(jl.Properties),
+				Tiles:      tiles,
+			})
+		case "objectgroup":
+			g := &ObjectGroup{
+				Name:       jl.Name,
+				Visible:    lv,
+				OffsetX:    ox,
+				OffsetY:    oy,
+				Properties: jsonProperties(jl.Properties),
+			}
+			for _, jo := range jl.Objects {
+				g.Objects = append(g.Objects, jo.object())
+			}
+			m.ObjectGroups = append(m.ObjectGroups, g)
+		case "group":
+			if err := m.addJSONLayers(jl.Layers, ox, oy, lv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tiles decodes layer data, plain arrays or base64 strings
+func (jl *jsonLayer) tiles() ([]Tile, error) {
+	if err := checkSize(jl.Width, jl.Height); err != nil {
+		return nil, err
+	}
+	if jl.Encoding == "base64" {
+		var s string
+		if err := json.Unmarshal(jl.Data, &s); err != nil {
+			return nil, err
+		}
+		return decodeBase64(s, jl.Compression, jl.Width*jl.Height)
+	}
+	var gids []uint32
+	if err := json.Unmarshal(jl.Data, &gids); err != nil {
+		return nil, err
+	}
+	tiles := make([]Tile, len(gids))
+	for i, gid := range gids {
+		tiles[i] = Tile(gid)
+	}
+	return tiles, nil
+}
+
+func (jt *jsonTileset) tileset(dir string) *Tileset {
+	ts := &Tileset{
+		Name:        jt.Name,
+		TileWidth:   jt.TileWidth,
+		TileHeight:  jt.TileHeight,
+		Spacing:     jt.Spacing,
+		Margin:      jt.Margin,
+		Columns:     jt.Columns,
+		TileCount:   jt.TileCount,
+		Image:       imagePath(dir, jt.Image),
+		ImageWidth:  jt.ImageWidth,
+		ImageHeight: jt.ImageHeight,
+		OffsetX:     jt.TileOffset.X,
+		OffsetY:     jt.TileOffset.Y,
+		Properties:  jsonProperties(jt.Properties),
+		Tiles:       make(map[int]*TileInfo),
+	}
+	for _, t := range jt.Tiles {
+		class := t.Class
+		if class == "" {
+			class = t.Type
+		}
+		ts.Tiles[t.ID] = &TileInfo{
+			ID:         t.ID,
+			Class:      class,
+			Image:      imagePath(dir, t.Image),
+			Width:      t.ImageWidth,
+			Height:     t.ImageHeight,
+			Properties: jsonProperties(t.Properties),
+		}
+	}
+	return ts
+}
+
+func (jo *jsonObject) object() *Object {
+	class := jo.Class
+	if class == "" {
+		class = jo.Type
+	}
+	return &Object{
+		ID:         jo.ID,
+		Name:       jo.Name,
+		Class:      class,
+		X:          jo.X,
+		Y:          jo.Y,
+		Width:      jo.Width,
+		Height:     jo.Height,
+		Rotation:   jo.Rotation,
+		GID:        Tile(jo.GID),
+		Point:      jo.Point,
+		Ellipse:    jo.Ellipse,
+		Visible:    jo.Visible == nil || *jo.Visible,
+		Properties: jsonProperties(jo.Properties),
+	}
+}
+
+// jsonProperties keeps strings as they are and other values as JSON text
+func jsonProperties(props []jsonProperty) Properties {
+	p := make(Properties, len(props))
+	for _, prop := range props {
+		var s string
+		if err := json.Unmarshal(prop.Value, &s); err == nil {
+			p[prop.Name] = s
+		} else {
+			p[prop.Name] = string(prop.Value)
+		}
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+package tilemap
+
+import "strconv"
+
+// Properties are custom properties set in Tiled, values are kept
+// as text and converted by the getters
+type Properties map[string]string
+
+// String returns property or def when it is not set
+func (p Properties) String(name, def string) string {
+	if v, ok := p[name]; ok {
+		return v
+	}
+	return def
+}
+
+// Int returns int or float property truncated, def when it is not set or bad
+func (p Properties) Int(name string, def int) int {
+	v, ok := p[name]
+	if !ok {
+		return def
+	}
+	if i, err := strconv.Atoi(v); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return int(f)
+	}
+	return def
+}
+
+// Float returns property or def when it is not set or bad
+func (p Properties) Float(name string, def float64) float64 {
+	if f, err := strconv.ParseFloat(p[name], 64); err == nil {
+		return f
+	}
+	return def
+}
+
+// Bool returns property or def when it is not set or bad
+func (p Properties) Bool(name string, def bool) bool {
+	if b, err := strconv.ParseBool(p[name]); err == nil {
+		return b
+	}
+	return def
+}
@@ -0,0 +1,10 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="terrain" tilewidth="16" tileheight="16" spacing="0" margin="0" tilecount="8">
+ <tileoffset x="0" y="2"/>
+ <image source="tiles.png" width="64" height="32"/>
+ <tile id="2" type="wall">
+  <properties>
+   <property name="solid" type="bool" value="true"/>
+  </properties>
+ </tile>
+</tileset>
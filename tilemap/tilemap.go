@@ -0,0 +1,322 @@
+package tilemap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Orientations of the map, only orthogonal ones are drawn
+const (
+	Orthogonal = "orthogonal"
+	Isometric  = "isometric"
+	Staggered  = "staggered"
+	Hexagonal  = "hexagonal"
+)
+
+// Errors of the map loading
+var (
+	ErrFormat   = errors.New("tilemap: unknown map format")
+	ErrInfinite = errors.New("tilemap: infinite maps are not supported")
+)
+
+// Flags stored in the top bits of the tile GID
+const (
+	FlipHorizontal uint32 = 0x80000000
+	FlipVertical   uint32 = 0x40000000
+	FlipDiagonal   uint32 = 0x20000000
+	// Hexagonal 120 degrees rotation, shares the bit with nothing else
+	RotateHex uint32 = 0x10000000
+	flipMask         = FlipHorizontal | FlipVertical | FlipDiagonal | RotateHex
+)
+
+// Tile is a global tile id with flip flags, 0 is an empty cell
+type Tile uint32
+
+// GID returns global tile id without flags
+func (t Tile) GID() int {
+	return int(uint32(t) &^ flipMask)
+}
+
+// Empty reports whether cell has no tile
+func (t Tile) Empty() bool {
+	return t.GID() == 0
+}
+
+// FlipH reports whether tile is mirrored horizontally
+func (t Tile) FlipH() bool {
+	return uint32(t)&FlipHorizontal != 0
+}
+
+// FlipV reports whether tile is mirrored vertically
+func (t Tile) FlipV() bool {
+	return uint32(t)&FlipVertical != 0
+}
+
+// FlipD reports whether tile is mirrored over the top left to bottom right diagonal
+func (t Tile) FlipD() bool {
+	return uint32(t)&FlipDiagonal != 0
+}
+
+// Map is a Tiled map, sizes are in tiles unless told otherwise
+type Map struct {
+	Width, Height int
+	// Grid cell size in pixels
+	TileWidth, TileHeight int
+	Orientation           string
+	// Background color as written by Tiled, #AARRGGBB or #RRGGBB
+	BackgroundColor string
+	Properties      Properties
+	// Sorted by FirstGID
+	Tilesets []*Tileset
+	// Tile and object layers in file order, groups are flattened
+	Layers       []*TileLayer
+	ObjectGroups []*ObjectGroup
+}
+
+// Tileset is a set of tiles cut from one image or a collection of images
+type Tileset struct {
+	// Global id of the first tile
+	FirstGID int
+	Name     string
+	// Size of a tile in pixels
+	TileWidth, TileHeight int
+	Spacing, Margin       int
+	Columns, TileCount    int
+	// Image path relative to the working directory, empty for collections
+	Image                   string
+	ImageWidth, ImageHeight int
+	// Offset of drawn tiles in pixels
+	OffsetX, OffsetY int
+	Properties       Properties
+	// Tiles with own data by local id
+	Tiles map[int]*TileInfo
+}
+
+// TileInfo holds data of a single tile of the tileset
+type TileInfo struct {
+	ID    int
+	Class string
+	// Own image of the tile in collections
+	Image         string
+	Width, Height int
+	Properties    Properties
+}
+
+// TileLayer is a grid of tiles, row by row
+type TileLayer struct {
+	Name             string
+	Width, Height    int
+	Visible          bool
+	Opacity          float64
+	OffsetX, OffsetY float64
+	Properties       Properties
+	Tiles            []Tile
+}
+
+// ObjectGroup is a layer of free placed objects
+type ObjectGroup struct {
+	Name             string
+	Visible          bool
+	OffsetX, OffsetY float64
+	Properties       Properties
+	Objects          []*Object
+}
+
+// Object is an entry of the object layer, X and Y are its top left
+// corner in pixels, tile objects are converted from bottom left
+type Object struct {
+	ID    int
+	Name  string
+	Class string
+	// Position and size in pixels, layer offset included
+	X, Y, Width, Height float64
+	// Rotation in degrees clockwise
+	Rotation float64
+	// Tile of tile objects, empty otherwise
+	GID            Tile
+	Point, Ellipse bool
+	Visible        bool
+	Properties     Properties
+}
+
+// Center returns middle of the object in pixels
+func (o *Object) Center() (float64, float64) {
+	return o.X + o.Width/2, o.Y + o.Height/2
+}
+
+// Load reads map from .tmx or .tmj/.json file, tileset and image
+// paths are resolved relative to the map
+func Load(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	var m *Map
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tmx", ".xml":
+		m, err = ParseTMX(data, dir)
+	case ".tmj", ".json":
+		m, err = ParseJSON(data, dir)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrFormat, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// loadTileset reads external .tsx or .tsj/.json tileset
+func loadTileset(path string) (*Tileset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	var ts *Tileset
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsx", ".xml":
+		ts, err = parseTMXTileset(data, dir)
+	case ".tsj", ".json":
+		ts, err = parseJSONTileset(data, dir)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrFormat, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ts, nil
+}
+
+// imagePath makes image path relative to the working directory
+func imagePath(dir, image string) string {
+	if image == "" || filepath.IsAbs(image) {
+		return image
+	}
+	return filepath.Join(dir, image)
+}
+
+// PixelSize returns map size in pixels
+func (m *Map) PixelSize() (int, int) {
+	return m.Width * m.TileWidth, m.Height * m.TileHeight
+}
+
+// Tileset returns tileset holding the tile and local id of the tile in it,
+// nil for empty or unknown tiles
+func (m *Map) Tileset(t Tile) (*Tileset, int) {
+	gid := t.GID()
+	if gid == 0 {
+		return nil, 0
+	}
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		ts := m.Tilesets[i]
+		if gid >= ts.FirstGID {
+			return ts, gid - ts.FirstGID
+		}
+	}
+	return nil, 0
+}
+
+// Layer returns tile layer by name
+func (m *Map) Layer(name string) *TileLayer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// ObjectGroup returns object layer by name
+func (m *Map) ObjectGroup(name string) *ObjectGroup {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Objects returns objects of the class from all object layers,
+// empty class returns all of them, hidden ones are skipped
+func (m *Map) Objects(class string) []*Object {
+	var objects []*Object
+	for _, g := range m.ObjectGroups {
+		if !g.Visible {
+			continue
+		}
+		for _, o := range g.Objects {
+			if o.Visible && (class == "" || o.Class == class) {
+				objects = append(objects, o)
+			}
+		}
+	}
+	return objects
+}
+
+// At returns tile of the layer cell, empty outside of the layer
+func (l *TileLayer) At(x, y int) Tile {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// Source returns part of the tileset image holding the tile,
+// for collection tiles it is the whole tile image
+func (ts *Tileset) Source(id int) (x, y, w, h int) {
+	if info := ts.Tiles[id]; info != nil && info.Image != "" {
+		return 0, 0, info.Width, info.Height
+	}
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	x = ts.Margin + (id%columns)*(ts.TileWidth+ts.Spacing)
+	y = ts.Margin + (id/columns)*(ts.TileHeight+ts.Spacing)
+	return x, y, ts.TileWidth, ts.TileHeight
+}
+
+// TileImage returns image holding the tile
+func (ts *Tileset) TileImage(id int) string {
+	if info := ts.Tiles[id]; info != nil && info.Image != "" {
+		return info.Image
+	}
+	return ts.Image
+}
+
+// finish checks map and fills values Tiled leaves out
+func (m *Map) finish() error {
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("tilemap: bad tile size %dx%d", m.TileWidth, m.TileHeight)
+	}
+	for _, ts := range m.Tilesets {
+		if ts.Columns == 0 && ts.Image != "" && ts.TileWidth > 0 {
+			ts.Columns = (ts.ImageWidth - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+		}
+	}
+	sort.Slice(m.Tilesets, func(i, j int) bool {
+		return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID
+	})
+	for _, l := range m.Layers {
+		if len(l.Tiles) != l.Width*l.Height {
+			return fmt.Errorf("tilemap: layer %q has %d tiles, want %d", l.Name, len(l.Tiles), l.Width*l.Height)
+		}
+	}
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			// Tile objects are anchored at the bottom left corner
+			if !o.GID.Empty() {
+				o.Y -= o.Height
+			}
+			o.X += g.OffsetX
+			o.Y += g.OffsetY
+		}
+	}
+	return nil
+}
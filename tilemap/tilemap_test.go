@@ -0,0 +1,137 @@
+package tilemap
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tiles of every layer in testdata, flags on the second row
+var wantTiles = []Tile{1, 2, 0, 0x80000003, 0x40000004, 0xA0000001}
+
+func TestLoad(t *testing.T) {
+	for _, file := range []string{"map.tmx", "map.tmj"} {
+		t.Run(file, func(t *testing.T) {
+			m, err := Load(filepath.Join("testdata", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkMap(t, m)
+		})
+	}
+}
+
+func checkMap(t *testing.T, m *Map) {
+	t.Helper()
+	if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.Orientation != Orthogonal || m.BackgroundColor != "#102030" {
+		t.Errorf("map header %+v", m)
+	}
+	if m.Properties.String("music", "") != "level1" || m.Properties.Float("gravity", 0) != 9.5 {
+		t.Errorf("map properties %v", m.Properties)
+	}
+
+	// Layers in file order with groups flattened
+	var names []string
+	for _, l := range m.Layers {
+		names = append(names, l.Name)
+		if !reflect.DeepEqual(l.Tiles, wantTiles) {
+			t.Errorf("layer %s tiles %x", l.Name, l.Tiles)
+		}
+	}
+	if want := []string{"csv", "base64", "zlib", "gzip", "xml"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("layers %v want %v", names, want)
+	}
+	if l := m.Layer("base64"); l.Opacity != 0.5 || !l.Visible {
+		t.Errorf("base64 layer opacity %v visible %v", l.Opacity, l.Visible)
+	}
+	if l := m.Layer("zlib"); l.Visible || l.OffsetX != 5 || l.OffsetY != 8 {
+		t.Errorf("grouped layer visible %v offset %v,%v", l.Visible, l.OffsetX, l.OffsetY)
+	}
+
+	// Flags
+	l := m.Layer("csv")
+	if tile := l.At(0, 1); !tile.FlipH() || tile.FlipV() || tile.GID() != 3 {
+		t.Errorf("tile 0,1 is %x", uint32(tile))
+	}
+	if tile := l.At(2, 1); !tile.FlipH() || !tile.FlipD() || tile.GID() != 1 {
+		t.Errorf("tile 2,1 is %x", uint32(tile))
+	}
+	if !l.At(2, 0).Empty() || !l.At(-1, 0).Empty() || !l.At(3, 0).Empty() {
+		t.Error("empty cells have tiles")
+	}
+
+	// External tileset resolved next to the map, columns filled in
+	ts, id := m.Tileset(l.At(0, 1))
+	if ts == nil || ts.Name != "terrain" || id != 2 {
+		t.Fatalf("tileset of gid 3 is %v, id %d", ts, id)
+	}
+	if ts.Image != filepath.Join("testdata", "tiles.png") || ts.Columns != 4 || ts.OffsetY != 2 {
+		t.Errorf("terrain image %s columns %d offset %d", ts.Image, ts.Columns, ts.OffsetY)
+	}
+	if x, y, w, h := ts.Source(5); x != 16 || y != 16 || w != 16 || h != 16 {
+		t.Errorf("source of tile 5 is %d,%d %dx%d", x, y, w, h)
+	}
+	if info := ts.Tiles[2]; info == nil || info.Class != "wall" || !info.Properties.Bool("solid", false) {
+		t.Errorf("wall tile %+v", info)
+	}
+	props, id := m.Tileset(9)
+	if props == nil || props.Name != "props" || id != 0 || props.TileImage(0) != filepath.Join("testdata", "crate.png") {
+		t.Errorf("tileset of gid 9 is %v, id %d", props, id)
+	}
+
+	// Objects with layer offset, tile objects moved to the top left
+	spawns := m.Objects("spawn")
+	if len(spawns) != 1 || spawns[0].Name != "player" || spawns[0].X != 26 || spawns[0].Y != 8 {
+		t.Fatalf("visible spawns %+v", spawns)
+	}
+	if spawns[0].Properties.Int("lives", 0) != 3 {
+		t.Errorf("player properties %v", spawns[0].Properties)
+	}
+	pickups := m.Objects("pickup")
+	if len(pickups) != 1 || pickups[0].X != 42 || pickups[0].Y != 0 || pickups[0].GID.GID() != 9 {
+		t.Errorf("pickups %+v", pickups)
+	}
+	if all := m.ObjectGroup("spawns").Objects; len(all) != 3 || !all[2].Point || all[2].Visible {
+		t.Errorf("hidden point object %+v", all)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	layer := func(size, data string) string {
+		return `<map width="2" height="1" tilewidth="8" tileheight="8"><layer name="l" ` + size + `>` + data + `</layer></map>`
+	}
+	tests := []struct {
+		name, tmx, want string
+	}{
+		{"negative size", layer(`width="-2" height="1"`, `<data encoding="base64" compression="zlib">AAAA</data>`), "bad layer size"},
+		{"huge size", layer(`width="100000" height="100000"`, `<data encoding="base64" compression="gzip">AAAA</data>`), "bad layer size"},
+		{"short data", layer(`width="2" height="1"`, `<data encoding="base64">AQAAAA==</data>`), "layer data"},
+		{"bad base64", layer(`width="2" height="1"`, `<data encoding="base64">!!</data>`), "layer data"},
+		{"bad compression", layer(`width="2" height="1"`, `<data encoding="base64" compression="zstd">AAAA</data>`), "unsupported compression"},
+		{"bad encoding", layer(`width="2" height="1"`, `<data encoding="hex">00</data>`), "unsupported encoding"},
+		{"count mismatch", layer(`width="2" height="1"`, `<data encoding="csv">1,2,3</data>`), "has 3 tiles"},
+		{"no data", layer(`width="2" height="1"`, ``), "no data"},
+		{"bad tile size", `<map width="1" height="1"></map>`, "bad tile size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTMX([]byte(tt.tmx), "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v want error with %q", err, tt.want)
+			}
+		})
+	}
+
+	json := `{"width":2,"height":1,"tilewidth":8,"tileheight":8,"layers":[{"type":"tilelayer","name":"l","width":-1,"height":-1,"encoding":"base64","data":"AAAA"}]}`
+	if _, err := ParseJSON([]byte(json), ""); err == nil || !strings.Contains(err.Error(), "bad layer size") {
+		t.Errorf("json negative size gave %v", err)
+	}
+	if _, err := ParseJSON([]byte(`{"infinite":true}`), ""); !errors.Is(err, ErrInfinite) {
+		t.Errorf("infinite map gave %v", err)
+	}
+	if _, err := Load(filepath.Join("testdata", "tiles.tsx")); !errors.Is(err, ErrFormat) {
+		t.Errorf("unknown extension gave %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package tiles
+
+import (
+	"fmt"
+	"math"
+	"sdl_learn/render"
+	"sdl_learn/tilemap"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Renderer draws tile layers of the map, it lives apart from tilemap
+// so maps load without SDL
+type Renderer struct {
+	Map *tilemap.Map
+	// Render queue layer of the tiles, tile layers are ordered by Z
+	Layer render.Layer
+	// Textures by image path
+	textures map[string]render.Texture
+	// Largest tile size, tiles bigger than the grid reach out of their cell
+	maxW, maxH int
+}
+
+// New loads images of all map tilesets
+func New(l render.Loader, m *tilemap.Map) (*Renderer, error) {
+	if m.Orientation != "" && m.Orientation != tilemap.Orthogonal {
+		return nil, fmt.Errorf("tiles: %s maps are not supported", m.Orientation)
+	}
+	r := &Renderer{
+		Map:      m,
+		Layer:    render.LayerBackground,
+		textures: make(map[string]render.Texture),
+		maxW:     m.TileWidth,
+		maxH:     m.TileHeight,
+	}
+	for _, ts := range m.Tilesets {
+		images := []string{ts.Image}
+		for _, info := range ts.Tiles {
+			images = append(images, info.Image)
+			r.maxW, r.maxH = max(r.maxW, info.Width), max(r.maxH, info.Height)
+		}
+		r.maxW, r.maxH = max(r.maxW, ts.TileWidth), max(r.maxH, ts.TileHeight)
+		for _, image := range images {
+			if image == "" || r.textures[image] != nil {
+				continue
+			}
+			t, err := l.LoadTexture(image)
+			if err != nil {
+				r.Free()
+				return nil, err
+			}
+			r.textures[image] = t
+		}
+	}
+	return r, nil
+}
+
+// Submit adds tiles of visible layers inside the world area to the queue
+func (r *Renderer) Submit(q *render.Queue, area sdl.FRect) {
+	m := r.Map
+	for z, l := range m.Layers {
+		if !l.Visible || l.Opacity <= 0 {
+			continue
+		}
+		// Tiles are anchored at the bottom left of the cell,
+		// so bigger ones may come from the cells below and left
+		left := float64(area.X) - l.OffsetX - float64(r.maxW-m.TileWidth)
+		top := float64(area.Y) - l.OffsetY
+		right := float64(area.X+area.W) - l.OffsetX
+		bottom := float64(area.Y+area.H) - l.OffsetY + float64(r.maxH-m.TileHeight)
+		x0 := max(int(math.Floor(left/float64(m.TileWidth))), 0)
+		y0 := max(int(math.Floor(top/float64(m.TileHeight))), 0)
+		x1 := min(int(math.Ceil(right/float64(m.TileWidth))), l.Width)
+		y1 := min(int(math.Ceil(bottom/float64(m.TileHeight))), l.Height)
+		// Partly transparent layers tint their tiles with the opacity
+		var tint sdl.Color
+		if l.Opacity < 1 {
+			tint = sdl.Color{R: 255, G: 255, B: 255, A: uint8(math.Round(l.Opacity * 255))}
+		}
+
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				tile := l.Tiles[y*l.Width+x]
+				if tile.Empty() {
+					continue
+				}
+				ts, id := m.Tileset(tile)
+				if ts == nil {
+					continue
+				}
+				texture := r.textures[ts.TileImage(id)]
+				if texture == nil {
+					continue
+				}
+				sx, sy, sw, sh := ts.Source(id)
+				o := options(tile)
+				o.Color = tint
+				q.Submit(render.Sprite{
+					Texture: texture,
+					Src:     &sdl.Rect{X: int32(sx), Y: int32(sy), W: int32(sw), H: int32(sh)},
+					Dst: sdl.Rect{
+						X: int32(x*m.TileWidth+ts.OffsetX) + int32(l.OffsetX),
+						Y: int32((y+1)*m.TileHeight-sh+ts.OffsetY) + int32(l.OffsetY),
+						W: int32(sw),
+						H: int32(sh),
+					},
+					Layer:   r.Layer,
+					Z:       int32(z),
+					Options: o,
+				})
+			}
+		}
+	}
+}
+
+// options turns Tiled flip flags into sprite options, the diagonal
+// flip is a quarter turn with flips swapped, right for square tiles
+func options(t tilemap.Tile) render.Options {
+	h, v := t.FlipH(), t.FlipV()
+	var o render.Options
+	if t.FlipD() {
+		o.Angle = 90
+		h, v = v, !h
+	}
+	if h {
+		o.Flip |= render.FlipHorizontal
+	}
+	if v {
+		o.Flip |= render.FlipVertical
+	}
+	return o
+}
+
+// Free releases tileset textures
+func (r *Renderer) Free() {
+	for image, t := range r.textures {
+		t.Free()
+		delete(r.textures, image)
+	}
+}
@@ -0,0 +1,70 @@
+package tiles
+
+import (
+	"path/filepath"
+	"sdl_learn/render"
+	"sdl_learn/tilemap"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestSubmit(t *testing.T) {
+	m, err := tilemap.Load(filepath.Join("..", "testdata", "map.tmx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := render.NewRecorder(64, 32)
+	r, err := New(rec, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Free()
+	q := render.NewQueue()
+	r.Submit(q, sdl.FRect{W: 48, H: 32})
+	q.Flush(rec, nil)
+
+	// Hidden group is skipped, three layers of five tiles are left
+	commands := rec.Commands()
+	if len(commands) != 15 {
+		t.Fatalf("got %d sprites want 15", len(commands))
+	}
+	half := sdl.Color{R: 255, G: 255, B: 255, A: 128}
+	for i, c := range commands {
+		// Half transparent base64 layer is the second one
+		want := sdl.Color{}
+		if i >= 5 && i < 10 {
+			want = half
+		}
+		if c.Options.Color != want {
+			t.Errorf("sprite %d color %v want %v", i, c.Options.Color, want)
+		}
+	}
+
+	// Tiles of the first layer by cell, tile offset moves them down
+	at := func(x, y int32) *render.Command {
+		for i := range commands[:5] {
+			if c := &commands[i]; c.Dst.X == x*16 && c.Dst.Y == y*16+2 {
+				return c
+			}
+		}
+		t.Fatalf("no tile at %d,%d", x, y)
+		return nil
+	}
+	if c := at(0, 0); c.Op != render.OpSprite || *c.Src != (sdl.Rect{W: 16, H: 16}) {
+		t.Errorf("plain tile %v src %v", c.Op, c.Src)
+	}
+	if c := at(1, 0); *c.Src != (sdl.Rect{X: 16, W: 16, H: 16}) {
+		t.Errorf("second tile src %v", c.Src)
+	}
+	if c := at(0, 1); c.Options.Flip != render.FlipHorizontal || c.Options.Angle != 0 {
+		t.Errorf("mirrored tile options %+v", c.Options)
+	}
+	if c := at(1, 1); c.Options.Flip != render.FlipVertical {
+		t.Errorf("flipped tile options %+v", c.Options)
+	}
+	// Horizontal and diagonal flips make a quarter turn
+	if c := at(2, 1); c.Options.Flip != 0 || c.Options.Angle != 90 {
+		t.Errorf("turned tile options %+v", c.Options)
+	}
+}
@@ -0,0 +1,297 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	// Multiline values are kept as element text
+	Text string `xml:",chardata"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	Image      *tmxImage     `xml:"image"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxTileset struct {
+	FirstGID   int    `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Margin     int    `xml:"margin,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+	TileOffset struct {
+		X int `xml:"x,attr"`
+		Y int `xml:"y,attr"`
+	} `xml:"tileoffset"`
+	Image      *tmxImage     `xml:"image"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Tiles      []tmxTile     `xml:"tile"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	// Tiles without encoding
+	Tiles []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Point      *struct{}     `xml:"point"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+// tmxLayer is any of layer, objectgroup or group elements,
+// they are decoded together to keep their order
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Opacity    string        `xml:"opacity,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       *tmxData      `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Layers     []tmxLayer    `xml:",any"`
+}
+
+type tmxMap struct {
+	Width           int           `xml:"width,attr"`
+	Height          int           `xml:"height,attr"`
+	TileWidth       int           `xml:"tilewidth,attr"`
+	TileHeight      int           `xml:"tileheight,attr"`
+	Orientation     string        `xml:"orientation,attr"`
+	Infinite        int           `xml:"infinite,attr"`
+	BackgroundColor string        `xml:"backgroundcolor,attr"`
+	Properties      []tmxProperty `xml:"properties>property"`
+	Tilesets        []tmxTileset  `xml:"tileset"`
+	Layers          []tmxLayer    `xml:",any"`
+}
+
+// ParseTMX reads map in Tiled XML format, dir is used to find
+// external tilesets and images
+func ParseTMX(data []byte, dir string) (*Map, error) {
+	var tm tmxMap
+	if err := xml.Unmarshal(data, &tm); err != nil {
+		return nil, err
+	}
+	if tm.Infinite != 0 {
+		return nil, ErrInfinite
+	}
+	m := &Map{
+		Width:           tm.Width,
+		Height:          tm.Height,
+		TileWidth:       tm.TileWidth,
+		TileHeight:      tm.TileHeight,
+		Orientation:     tm.Orientation,
+		BackgroundColor: tm.BackgroundColor,
+		Properties:      tmxProperties(tm.Properties),
+	}
+	for _, tt := range tm.Tilesets {
+		var ts *Tileset
+		if tt.Source != "" {
+			var err error
+			ts, err = loadTileset(filepath.Join(dir, tt.Source))
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			ts = tt.tileset(dir)
+		}
+		ts.FirstGID = tt.FirstGID
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	if err := m.addTMXLayers(tm.Layers, 0, 0, true); err != nil {
+		return nil, err
+	}
+	return m, m.finish()
+}
+
+// parseTMXTileset reads external tileset in Tiled XML format
+func parseTMXTileset(data []byte, dir string) (*Tileset, error) {
+	var tt tmxTileset
+	if err := xml.Unmarshal(data, &tt); err != nil {
+		return nil, err
+	}
+	return tt.tileset(dir), nil
+}
+
+// addTMXLayers flattens layers, group offset and visibility are
+// passed to the children
+func (m *Map) addTMXLayers(layers []tmxLayer, offsetX, offsetY float64, visible bool) error {
+	for _, tl := range layers {
+		lv := visible && tl.Visible != "0"
+		ox, oy := offsetX+tl.OffsetX, offsetY+tl.OffsetY
+		switch tl.XMLName.Local {
+		case "layer":
+			tiles, err := tl.tiles()
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", tl.Name, err)
+			}
+			opacity := 1.0
+			if v, err := strconv.ParseFloat(tl.Opacity, 64); err == nil {
+				opacity = v
+			}
+			m.Layers = append(m.Layers, &TileLayer{
+				Name:       tl.Name,
+				Width:      tl.Width,
+				Height:     tl.Height,
+				Visible:    lv,
+				Opacity:    opacity,
+				OffsetX:    ox,
+				OffsetY:    oy,
+				Properties: tmxProperties(tl.Properties),
+				Tiles:      tiles,
+			})
+		case "objectgroup":
+			g := &ObjectGroup{
+				Name:       tl.Name,
+				Visible:    lv,
+				OffsetX:    ox,
+				OffsetY:    oy,
+				Properties: tmxProperties(tl.Properties),
+			}
+			for _, to := range tl.Objects {
+				g.Objects = append(g.Objects, to.object())
+			}
+			m.ObjectGroups = append(m.ObjectGroups, g)
+		case "group":
+			if err := m.addTMXLayers(tl.Layers, ox, oy, lv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tiles decodes layer data in any of the TMX encodings
+func (tl *tmxLayer) tiles() ([]Tile, error) {
+	if tl.Data == nil {
+		return nil, fmt.Errorf("tilemap: layer has no data")
+	}
+	if err := checkSize(tl.Width, tl.Height); err != nil {
+		return nil, err
+	}
+	switch tl.Data.Encoding {
+	case "base64":
+		return decodeBase64(tl.Data.Text, tl.Data.Compression, tl.Width*tl.Height)
+	case "csv":
+		return decodeCSV(tl.Data.Text)
+	case "":
+		tiles := make([]Tile, len(tl.Data.Tiles))
+		for i, t := range tl.Data.Tiles {
+			tiles[i] = Tile(t.GID)
+		}
+		return tiles, nil
+	}
+	return nil, fmt.Errorf("tilemap: unsupported encoding %q", tl.Data.Encoding)
+}
+
+func (tt *tmxTileset) tileset(dir string) *Tileset {
+	ts := &Tileset{
+		Name:       tt.Name,
+		TileWidth:  tt.TileWidth,
+		TileHeight: tt.TileHeight,
+		Spacing:    tt.Spacing,
+		Margin:     tt.Margin,
+		Columns:    tt.Columns,
+		TileCount:  tt.TileCount,
+		OffsetX:    tt.TileOffset.X,
+		OffsetY:    tt.TileOffset.Y,
+		Properties: tmxProperties(tt.Properties),
+		Tiles:      make(map[int]*TileInfo),
+	}
+	if tt.Image != nil {
+		ts.Image = imagePath(dir, tt.Image.Source)
+		ts.ImageWidth, ts.ImageHeight = tt.Image.Width, tt.Image.Height
+	}
+	for _, t := range tt.Tiles {
+		class := t.Class
+		if class == "" {
+			class = t.Type
+		}
+		info := &TileInfo{
+			ID:         t.ID,
+			Class:      class,
+			Properties: tmxProperties(t.Properties),
+		}
+		if t.Image != nil {
+			info.Image = imagePath(dir, t.Image.Source)
+			info.Width, info.Height = t.Image.Width, t.Image.Height
+		}
+		ts.Tiles[t.ID] = info
+	}
+	return ts
+}
+
+func (to *tmxObject) object() *Object {
+	class := to.Class
+	if class == "" {
+		class = to.Type
+	}
+	return &Object{
+		ID:         to.ID,
+		Name:       to.Name,
+		Class:      class,
+		X:          to.X,
+		Y:          to.Y,
+		Width:      to.Width,
+		Height:     to.Height,
+		Rotation:   to.Rotation,
+		GID:        Tile(to.GID),
+		Point:      to.Point != nil,
+		Ellipse:    to.Ellipse != nil,
+		Visible:    to.Visible != "0",
+		Properties: tmxProperties(to.Properties),
+	}
+}
+
+// tmxProperties takes value attribute or element text of multiline values
+func tmxProperties(props []tmxProperty) Properties {
+	p := make(Properties, len(props))
+	for _, prop := range props {
+		if prop.Value == "" && strings.TrimSpace(prop.Text) != "" {
+			p[prop.Name] = prop.Text
+		} else {
+			p[prop.Name] = prop.Value
+		}
+	}
+	return p
+}
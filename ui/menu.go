@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"sdl_learn/inputs"
+	"sdl_learn/text"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Menu is a vertical list of options navigated by keyboard
+type Menu struct {
+	Title    string
+	Items    []string
+	Selected int
+}
+
+// NewMenu creates menu with the first item selected
+func NewMenu(title string, items ...string) *Menu {
+	return &Menu{
+		Title: title,
+		Items: items,
+	}
+}
+
+// HandleEvent moves selection and returns index of the chosen item,
+// -1 when nothing was chosen
+func (m *Menu) HandleEvent(e sdl.Event) int {
+	if len(m.Items) == 0 {
+		return -1
+	}
+	switch {
+	case inputs.Pressed(e, sdl.SCANCODE_UP) || inputs.Pressed(e, sdl.SCANCODE_W):
+		m.Selected = (m.Selected + len(m.Items) - 1) % len(m.Items)
+	case inputs.Pressed(e, sdl.SCANCODE_DOWN) || inputs.Pressed(e, sdl.SCANCODE_S):
+		m.Selected = (m.Selected + 1) % len(m.Items)
+	case inputs.Pressed(e, sdl.SCANCODE_RETURN) || inputs.Pressed(e, sdl.SCANCODE_KP_ENTER):
+		return m.Selected
+	}
+	return -1
+}
+
+// Draw renders title and items centered on the screen
+func (m *Menu) Draw(r *sdl.Renderer, f text.Font) {
+	x, y := Center(r)
+	line := f.LineHeight()
+	y -= line * int32(len(m.Items)+2) / 2
+	if m.Title != "" {
+		text.DrawAligned(r, f, m.Title, x, y, text.Center, Yellow)
+	}
+	y += line * 2
+	for i, item := range m.Items {
+		color := Grey
+		if i == m.Selected {
+			color = White
+			item = "> " + item + " <"
+		}
+		text.DrawAligned(r, f, item, x, y, text.Center, color)
+		y += line
+	}
+}
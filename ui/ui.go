@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Colors shared by screens
+var (
+	White    = sdl.Color{R: 255, G: 255, B: 255, A: 255}
+	Grey     = sdl.Color{R: 160, G: 160, B: 160, A: 255}
+	Yellow   = sdl.Color{R: 255, G: 220, B: 0, A: 255}
+	DimColor = sdl.Color{R: 0, G: 0, B: 0, A: 160}
+)
+
+// Dim covers the whole output with translucent color
+func Dim(r *sdl.Renderer, color sdl.Color) {
+	var mode sdl.BlendMode
+	r.GetDrawBlendMode(&mode)
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	r.SetDrawColor(color.R, color.G, color.B, color.A)
+	r.FillRect(nil)
+	r.SetDrawBlendMode(mode)
+}
+
+// Center returns middle point of the render output
+func Center(r *sdl.Renderer) (int32, int32) {
+	w, h := r.GetLogicalSize()
+	if w == 0 || h == 0 {
+		w, h, _ = r.GetOutputSize()
+	}
+	return w / 2, h / 2
+}